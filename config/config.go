@@ -9,13 +9,26 @@ import (
 
 const (
 	configPath = "./config.json"
+
+	defaultGovernanceStartBlock   = 4893100
+	defaultGovernanceCycleBlocks  = 210
+	defaultGovernancePeriodCycles = 820
+
+	defaultPendingInterval = 5
 )
 
+// defaultGovernanceReminders are blocks before the governance period end (~1 day and ~1 hour).
+var defaultGovernanceReminders = []uint64{5760, 240}
+
 type (
 	Config struct {
-		Mysql         Mysql  `json:"mysql"`
-		TelegramToken string `json:"telegram_token"`
-		Node          string `json:"node"`
+		Mysql         Mysql      `json:"mysql"`
+		TelegramToken string     `json:"telegram_token"`
+		Node          string     `json:"node"`
+		API           API        `json:"api"`
+		Governance    Governance `json:"governance"`
+		Rewards       Rewards    `json:"rewards"`
+		Pending       Pending    `json:"pending"`
 	}
 	Mysql struct {
 		Host     string `json:"host"`
@@ -24,6 +37,23 @@ type (
 		User     string `json:"user"`
 		Password string `json:"password"`
 	}
+	API struct {
+		Listen string `json:"listen"`
+	}
+	Governance struct {
+		StartBlock   uint64   `json:"start_block"`   // first block of the first governance period
+		CycleBlocks  uint64   `json:"cycle_blocks"`  // blocks in a polling cycle
+		PeriodCycles uint64   `json:"period_cycles"` // polling cycles in a governance period
+		Contract     string   `json:"contract"`      // transactions of gov managers to the contract count as participation
+		Reminders    []uint64 `json:"reminders"`     // blocks before the period end
+	}
+	Pending struct {
+		Enabled  bool `json:"enabled"`  // alert about transactions in the tail blocks before they are irreversible
+		Interval int  `json:"interval"` // polling interval in seconds
+	}
+	Rewards struct {
+		Sources []string `json:"sources"` // addresses distributing NAX rewards
+	}
 )
 
 func GetConfig() Config {
@@ -37,5 +67,24 @@ func GetConfig() Config {
 	if err != nil {
 		log.Fatalln("Failed unmarshal config ", err)
 	}
+	config.Governance.setDefaults()
+	if config.Pending.Interval <= 0 {
+		config.Pending.Interval = defaultPendingInterval
+	}
 	return config
 }
+
+func (g *Governance) setDefaults() {
+	if g.StartBlock == 0 {
+		g.StartBlock = defaultGovernanceStartBlock
+	}
+	if g.CycleBlocks == 0 {
+		g.CycleBlocks = defaultGovernanceCycleBlocks
+	}
+	if g.PeriodCycles == 0 {
+		g.PeriodCycles = defaultGovernancePeriodCycles
+	}
+	if g.Reminders == nil {
+		g.Reminders = defaultGovernanceReminders
+	}
+}
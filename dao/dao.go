@@ -6,6 +6,7 @@ import (
 	"github.com/everstake/nebulas-tg-bot/dao/filters"
 	"github.com/everstake/nebulas-tg-bot/dao/mysql"
 	"github.com/everstake/nebulas-tg-bot/models"
+	"time"
 )
 
 type (
@@ -20,10 +21,45 @@ type (
 		GetAddresses(filter filters.Addresses) (addresses []models.Address, err error)
 		CreateAddress(address models.Address) (models.Address, error)
 		CreateUserAddress(userAddress models.UserAddress) error
+		UpdateUserAddress(userAddress models.UserAddress) error
 		GetUsersAddresses(filter filters.UsersAddresses) (usersAddresses []models.UserAddress, err error)
 		GetUsersAddressReports(filter filters.UsersAddresses) (items []models.UserAddressReport, err error)
 		DeleteUserAddress(userID uint64, addressID uint64) error
 
+		GetUsersNodes(filter filters.UsersNodes) (usersNodes []models.UserNode, err error)
+		CreateUserNode(userNode models.UserNode) error
+		UpdateUserNode(userNode models.UserNode) error
+		DeleteUserNode(userID uint64, nodeID string) error
+
+		GetVotesSnapshots(filter filters.VotesSnapshots) (items []models.VoteSnapshot, err error)
+		ReplaceVotesSnapshot(nodeID string, items []models.VoteSnapshot) error
+
+		CreateStabilityIndexes(items []models.StabilityIndex) error
+		GetLastStabilityIndexes() (items []models.StabilityIndex, err error)
+		DeleteStabilityIndexes(before time.Time) error
+		GetStabilityAlerts() (items []models.StabilityAlert, err error)
+		CreateStabilityAlert(alert models.StabilityAlert) error
+		DeleteStabilityAlert(userID uint64, nodeID string) error
+
+		GetGovernancePeriod(number uint64) (period models.GovernancePeriod, err error)
+		CreateGovernancePeriod(period models.GovernancePeriod) error
+		UpdateGovernancePeriod(period models.GovernancePeriod) error
+		GetGovernanceParticipants(filter filters.GovernanceParticipants) (items []models.GovernanceParticipant, err error)
+		CreateGovernanceParticipants(items []models.GovernanceParticipant) error
+		UpdateGovernanceParticipant(item models.GovernanceParticipant) error
+
+		CreateReward(reward models.Reward) error
+		GetAddressesRewards(filter filters.Rewards) (items []models.RewardTotal, err error)
+		GetNodesRewards(filter filters.Rewards) (items []models.RewardTotal, err error)
+
+		GetPriceAlerts(filter filters.PriceAlerts) (items []models.PriceAlert, err error)
+		CreatePriceAlert(alert models.PriceAlert) (models.PriceAlert, error)
+		UpdatePriceAlert(alert models.PriceAlert) error
+		DeletePriceAlert(userID uint64, id uint64) error
+
+		CreatePrices(items []models.Price) error
+		GetPrices(filter filters.Prices) (items []models.Price, err error)
+
 		UpdateState(state models.State) error
 		GetState(title string) (state models.State, err error)
 	}
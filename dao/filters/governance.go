@@ -0,0 +1,6 @@
+package filters
+
+type GovernanceParticipants struct {
+	PeriodNumbers []uint64
+	NodeIDs       []string
+}
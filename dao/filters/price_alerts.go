@@ -0,0 +1,6 @@
+package filters
+
+type PriceAlerts struct {
+	IDs     []uint64
+	UserIDs []uint64
+}
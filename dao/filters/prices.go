@@ -0,0 +1,8 @@
+package filters
+
+import "time"
+
+type Prices struct {
+	Assets []string
+	From   time.Time
+}
@@ -0,0 +1,9 @@
+package filters
+
+import "time"
+
+type Rewards struct {
+	Addresses []string
+	NodeIDs   []string
+	From      time.Time
+}
@@ -1,6 +1,7 @@
 package filters
 
 type Users struct {
-	IDs   []uint64
-	TgIDs []int64
+	IDs       []uint64
+	TgIDs     []int64
+	APITokens []string
 }
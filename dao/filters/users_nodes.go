@@ -0,0 +1,6 @@
+package filters
+
+type UsersNodes struct {
+	UserIDs []uint64
+	NodeIDs []string
+}
@@ -0,0 +1,6 @@
+package filters
+
+type VotesSnapshots struct {
+	NodeIDs   []string
+	Addresses []string
+}
@@ -26,15 +26,33 @@ func (m DB) CreateAddress(address models.Address) (models.Address, error) {
 
 func (m DB) CreateUserAddress(userAddress models.UserAddress) error {
 	q := squirrel.Insert(models.UserAddressesTable).SetMap(map[string]interface{}{
-		"usr_id":    userAddress.UserID,
-		"adr_id":    userAddress.AddressID,
-		"usa_alias": userAddress.Alias,
-		"usa_type":  userAddress.Type,
+		"usr_id":        userAddress.UserID,
+		"adr_id":        userAddress.AddressID,
+		"usa_alias":     userAddress.Alias,
+		"usa_type":      userAddress.Type,
+		"usa_webhook":   userAddress.Webhook,
+		"usa_functions": userAddress.Functions,
+		"usa_args":      userAddress.Args,
+		"usa_events":    userAddress.Events,
 	})
 	_, err := m.insert(q)
 	return err
 }
 
+func (m DB) UpdateUserAddress(userAddress models.UserAddress) error {
+	q := squirrel.Update(models.UserAddressesTable).SetMap(map[string]interface{}{
+		"usa_alias":     userAddress.Alias,
+		"usa_type":      userAddress.Type,
+		"usa_webhook":   userAddress.Webhook,
+		"usa_functions": userAddress.Functions,
+		"usa_args":      userAddress.Args,
+		"usa_events":    userAddress.Events,
+	}).
+		Where(squirrel.Eq{"usr_id": userAddress.UserID}).
+		Where(squirrel.Eq{"adr_id": userAddress.AddressID})
+	return m.update(q)
+}
+
 func (m DB) GetUsersAddresses(filter filters.UsersAddresses) (usersAddresses []models.UserAddress, err error) {
 	q := squirrel.Select("*").From(models.UserAddressesTable)
 	if len(filter.UserID) != 0 {
@@ -53,9 +71,14 @@ func (m DB) GetUsersAddressReports(filter filters.UsersAddresses) (items []model
 		"addresses.adr_address as address",
 		"users_addresses.usa_alias as alias",
 		"users_addresses.usa_type as type",
+		"users_addresses.usa_webhook as webhook",
+		"users_addresses.usa_functions as functions",
+		"users_addresses.usa_args as args",
+		"users_addresses.usa_events as events",
 		"addresses.adr_created_at as created_at",
 	).From(models.UserAddressesTable).
-		LeftJoin("addresses ON addresses.adr_id = users_addresses.adr_id")
+		LeftJoin("addresses ON addresses.adr_id = users_addresses.adr_id").
+		OrderBy("users_addresses.adr_id")
 	if len(filter.UserID) != 0 {
 		q = q.Where(squirrel.Eq{"users_addresses.usr_id": filter.UserID})
 	}
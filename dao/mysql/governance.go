@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+)
+
+func (m DB) GetGovernancePeriod(number uint64) (period models.GovernancePeriod, err error) {
+	q := squirrel.Select("*").From(models.GovernancePeriodsTable).Where(squirrel.Eq{"gvp_number": number})
+	err = m.first(&period, q)
+	return period, err
+}
+
+func (m DB) CreateGovernancePeriod(period models.GovernancePeriod) error {
+	q := squirrel.Insert(models.GovernancePeriodsTable).SetMap(map[string]interface{}{
+		"gvp_number":      period.Number,
+		"gvp_start_block": period.StartBlock,
+		"gvp_end_block":   period.EndBlock,
+	})
+	_, err := m.insert(q)
+	return err
+}
+
+func (m DB) UpdateGovernancePeriod(period models.GovernancePeriod) error {
+	q := squirrel.Update(models.GovernancePeriodsTable).SetMap(map[string]interface{}{
+		"gvp_reminded_block": period.RemindedBlock,
+		"gvp_finished":       period.Finished,
+	}).Where(squirrel.Eq{"gvp_number": period.Number})
+	return m.update(q)
+}
+
+func (m DB) GetGovernanceParticipants(filter filters.GovernanceParticipants) (items []models.GovernanceParticipant, err error) {
+	q := squirrel.Select("*").From(models.GovernanceParticipantsTable)
+	if len(filter.PeriodNumbers) != 0 {
+		q = q.Where(squirrel.Eq{"gvp_number": filter.PeriodNumbers})
+	}
+	if len(filter.NodeIDs) != 0 {
+		q = q.Where(squirrel.Eq{"gpt_node_id": filter.NodeIDs})
+	}
+	err = m.find(&items, q)
+	return items, err
+}
+
+func (m DB) CreateGovernanceParticipants(items []models.GovernanceParticipant) error {
+	if len(items) == 0 {
+		return nil
+	}
+	q := squirrel.Insert(models.GovernanceParticipantsTable).Columns("gvp_number", "gpt_node_id", "gpt_gov_manager")
+	for _, item := range items {
+		q = q.Values(item.PeriodNumber, item.NodeID, item.GovManager)
+	}
+	_, err := m.insert(q)
+	return err
+}
+
+func (m DB) UpdateGovernanceParticipant(item models.GovernanceParticipant) error {
+	q := squirrel.Update(models.GovernanceParticipantsTable).SetMap(map[string]interface{}{
+		"gpt_participated": item.Participated,
+		"gpt_block":        item.Block,
+	}).Where(squirrel.Eq{"gvp_number": item.PeriodNumber}).Where(squirrel.Eq{"gpt_node_id": item.NodeID})
+	return m.update(q)
+}
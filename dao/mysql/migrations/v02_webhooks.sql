@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE `users`
+    ADD `usr_webhook_secret` varchar(64) NOT NULL DEFAULT '' AFTER `usr_max_threshold`;
+
+ALTER TABLE `users_addresses`
+    ADD `usa_webhook` varchar(255) NOT NULL DEFAULT '' AFTER `usa_type`;
+
+-- +migrate Down
+ALTER TABLE `users_addresses`
+    DROP COLUMN `usa_webhook`;
+
+ALTER TABLE `users`
+    DROP COLUMN `usr_webhook_secret`;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE `users`
+    ADD `usr_api_token` varchar(64) NOT NULL DEFAULT '' AFTER `usr_webhook_secret`,
+    ADD KEY `users_usr_api_token_index` (`usr_api_token`);
+
+-- +migrate Down
+ALTER TABLE `users`
+    DROP KEY `users_usr_api_token_index`,
+    DROP COLUMN `usr_api_token`;
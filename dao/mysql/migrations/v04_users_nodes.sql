@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE `users_nodes`
+(
+    `usr_id`         int(11)      NOT NULL,
+    `usn_node_id`    varchar(255) NOT NULL,
+    `usn_alias`      varchar(255) NOT NULL DEFAULT '',
+    `usn_created_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `users_nodes_usr_id_usn_node_id_uindex` (`usr_id`, `usn_node_id`),
+    KEY `users_nodes_usn_node_id_index` (`usn_node_id`),
+    CONSTRAINT `users_nodes_users_usr_id_fk` FOREIGN KEY (`usr_id`) REFERENCES `users` (`usr_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- +migrate Down
+drop table users_nodes;
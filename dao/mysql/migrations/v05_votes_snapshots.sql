@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE `votes_snapshots`
+(
+    `vts_node_id`    varchar(255)    NOT NULL,
+    `vts_address`    varchar(35)     NOT NULL,
+    `vts_value`      decimal(40, 10) NOT NULL DEFAULT '0.0000000000',
+    `vts_created_at` timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`vts_node_id`, `vts_address`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+ALTER TABLE `users`
+    ADD `usr_whale_share` decimal(5, 2) NOT NULL DEFAULT '0.00' AFTER `usr_api_token`;
+
+-- +migrate Down
+ALTER TABLE `users`
+    DROP COLUMN `usr_whale_share`;
+
+drop table votes_snapshots;
@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE `stability_indexes`
+(
+    `sti_id`         int(11)      NOT NULL AUTO_INCREMENT,
+    `sti_node_id`    varchar(255) NOT NULL,
+    `sti_value`      double       NOT NULL DEFAULT '0',
+    `sti_created_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`sti_id`),
+    KEY `stability_indexes_sti_node_id_index` (`sti_node_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE `stability_alerts`
+(
+    `usr_id`            int(11)       NOT NULL,
+    `sta_node_id`       varchar(255)  NOT NULL,
+    `sta_recover_level` decimal(5, 4) NOT NULL DEFAULT '0.0000',
+    `sta_created_at`    timestamp     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`usr_id`, `sta_node_id`),
+    CONSTRAINT `stability_alerts_users_usr_id_fk` FOREIGN KEY (`usr_id`) REFERENCES `users` (`usr_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+ALTER TABLE `users`
+    ADD `usr_stability_level` decimal(5, 4) NOT NULL DEFAULT '0.9500' AFTER `usr_whale_share`,
+    ADD `usr_stability_drop`  decimal(5, 4) NOT NULL DEFAULT '0.0100' AFTER `usr_stability_level`;
+
+-- +migrate Down
+ALTER TABLE `users`
+    DROP COLUMN `usr_stability_level`,
+    DROP COLUMN `usr_stability_drop`;
+
+drop table stability_alerts;
+drop table stability_indexes;
@@ -0,0 +1,29 @@
+-- +migrate Up
+CREATE TABLE `governance_periods`
+(
+    `gvp_number`      int(11)    NOT NULL,
+    `gvp_start_block` bigint(20) NOT NULL,
+    `gvp_end_block`   bigint(20) NOT NULL,
+    `gvp_created_at`  timestamp  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`gvp_number`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE `governance_participants`
+(
+    `gvp_number`       int(11)      NOT NULL,
+    `gpt_node_id`      varchar(255) NOT NULL,
+    `gpt_gov_manager`  varchar(35)  NOT NULL DEFAULT '',
+    `gpt_participated` tinyint(1)   NOT NULL DEFAULT '0',
+    `gpt_block`        bigint(20)   NOT NULL DEFAULT '0',
+    `gpt_created_at`   timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`gvp_number`, `gpt_node_id`),
+    CONSTRAINT `governance_participants_governance_periods_gvp_number_fk` FOREIGN KEY (`gvp_number`) REFERENCES `governance_periods` (`gvp_number`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- +migrate Down
+drop table governance_participants;
+drop table governance_periods;
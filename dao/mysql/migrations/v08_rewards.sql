@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE `rewards`
+(
+    `rwd_id`         int(11)         NOT NULL AUTO_INCREMENT,
+    `rwd_address`    varchar(35)     NOT NULL,
+    `rwd_node_id`    varchar(255)    NOT NULL DEFAULT '',
+    `rwd_value`      decimal(40, 10) NOT NULL DEFAULT '0.0000000000',
+    `rwd_tx_hash`    varchar(64)     NOT NULL,
+    `rwd_block`      bigint(20)      NOT NULL DEFAULT '0',
+    `rwd_created_at` timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`rwd_id`),
+    UNIQUE KEY `rewards_rwd_tx_hash_rwd_address_uindex` (`rwd_tx_hash`, `rwd_address`),
+    KEY `rewards_rwd_address_index` (`rwd_address`),
+    KEY `rewards_rwd_node_id_index` (`rwd_node_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- +migrate Down
+drop table rewards;
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE `price_alerts`
+(
+    `pra_id`           int(11)                          NOT NULL AUTO_INCREMENT,
+    `usr_id`           int(11)                          NOT NULL,
+    `pra_asset`        varchar(10)                      NOT NULL,
+    `pra_kind`         enum ('above','below','change') NOT NULL,
+    `pra_value`        decimal(30, 10)                  NOT NULL DEFAULT '0.0000000000',
+    `pra_window`       int(11)                          NOT NULL DEFAULT '0',
+    `pra_recurring`    tinyint(1)                       NOT NULL DEFAULT '0',
+    `pra_armed`        tinyint(1)                       NOT NULL DEFAULT '1',
+    `pra_triggered_at` timestamp                        NULL     DEFAULT NULL,
+    `pra_created_at`   timestamp                        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`pra_id`),
+    KEY `price_alerts_usr_id_index` (`usr_id`),
+    CONSTRAINT `price_alerts_users_usr_id_fk` FOREIGN KEY (`usr_id`) REFERENCES `users` (`usr_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- +migrate Down
+drop table price_alerts;
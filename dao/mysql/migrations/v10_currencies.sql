@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE `users`
+    ADD `usr_currency` varchar(10) NOT NULL DEFAULT 'USD' AFTER `usr_lang`;
+
+ALTER TABLE `price_alerts`
+    ADD `pra_currency` varchar(10) NOT NULL DEFAULT 'USD' AFTER `pra_value`;
+
+-- +migrate Down
+ALTER TABLE `price_alerts`
+    DROP COLUMN `pra_currency`;
+
+ALTER TABLE `users`
+    DROP COLUMN `usr_currency`;
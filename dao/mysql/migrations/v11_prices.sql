@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE `prices`
+(
+    `prc_id`         int(11)         NOT NULL AUTO_INCREMENT,
+    `prc_asset`      varchar(10)     NOT NULL,
+    `prc_value`      decimal(30, 10) NOT NULL DEFAULT '0.0000000000',
+    `prc_created_at` timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`prc_id`),
+    KEY `prices_prc_asset_prc_created_at_index` (`prc_asset`, `prc_created_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- +migrate Down
+drop table prices;
@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE `users_addresses`
+    MODIFY `usa_type` enum ('account','validator','contract') NOT NULL,
+    ADD `usa_functions` varchar(1024) NOT NULL DEFAULT '' AFTER `usa_webhook`,
+    ADD `usa_args` varchar(1024) NOT NULL DEFAULT '' AFTER `usa_functions`,
+    ADD `usa_events` tinyint(1) NOT NULL DEFAULT '0' AFTER `usa_args`;
+
+-- +migrate Down
+DELETE FROM `users_addresses` WHERE `usa_type` = 'contract';
+
+ALTER TABLE `users_addresses`
+    DROP COLUMN `usa_events`,
+    DROP COLUMN `usa_args`,
+    DROP COLUMN `usa_functions`,
+    MODIFY `usa_type` enum ('account','validator') NOT NULL;
@@ -0,0 +1,14 @@
+-- +migrate Up
+ALTER TABLE `governance_periods`
+    ADD `gvp_reminded_block` bigint(20) NOT NULL DEFAULT '0' AFTER `gvp_end_block`,
+    ADD `gvp_finished`       tinyint(1) NOT NULL DEFAULT '0' AFTER `gvp_reminded_block`;
+
+-- the results of the past periods have been announced already
+UPDATE `governance_periods`
+SET `gvp_finished` = 1
+WHERE `gvp_number` < (SELECT `last`.`number` FROM (SELECT MAX(`gvp_number`) AS `number` FROM `governance_periods`) AS `last`);
+
+-- +migrate Down
+ALTER TABLE `governance_periods`
+    DROP COLUMN `gvp_finished`,
+    DROP COLUMN `gvp_reminded_block`;
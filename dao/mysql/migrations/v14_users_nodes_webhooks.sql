@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE `users_nodes`
+    ADD `usn_webhook` varchar(255) NOT NULL DEFAULT '' AFTER `usn_alias`;
+
+-- +migrate Down
+ALTER TABLE `users_nodes`
+    DROP COLUMN `usn_webhook`;
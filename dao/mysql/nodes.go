@@ -0,0 +1,50 @@
+package mysql
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+)
+
+func (m DB) GetUsersNodes(filter filters.UsersNodes) (usersNodes []models.UserNode, err error) {
+	q := squirrel.Select("*").From(models.UsersNodesTable)
+	if len(filter.UserIDs) != 0 {
+		q = q.Where(squirrel.Eq{"usr_id": filter.UserIDs})
+	}
+	if len(filter.NodeIDs) != 0 {
+		q = q.Where(squirrel.Eq{"usn_node_id": filter.NodeIDs})
+	}
+	err = m.find(&usersNodes, q)
+	return usersNodes, err
+}
+
+func (m DB) CreateUserNode(userNode models.UserNode) error {
+	q := squirrel.Insert(models.UsersNodesTable).SetMap(map[string]interface{}{
+		"usr_id":      userNode.UserID,
+		"usn_node_id": userNode.NodeID,
+		"usn_alias":   userNode.Alias,
+	})
+	_, err := m.insert(q)
+	return err
+}
+
+func (m DB) UpdateUserNode(userNode models.UserNode) error {
+	q := squirrel.Update(models.UsersNodesTable).SetMap(map[string]interface{}{
+		"usn_webhook": userNode.Webhook,
+	}).
+		Where(squirrel.Eq{"usr_id": userNode.UserID}).
+		Where(squirrel.Eq{"usn_node_id": userNode.NodeID})
+	return m.update(q)
+}
+
+func (m DB) DeleteUserNode(userID uint64, nodeID string) error {
+	q := squirrel.Delete(models.UsersNodesTable).
+		Where(squirrel.Eq{"usr_id": userID}).
+		Where(squirrel.Eq{"usn_node_id": nodeID})
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(sql, args...)
+	return err
+}
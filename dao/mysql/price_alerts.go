@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+)
+
+func (m DB) GetPriceAlerts(filter filters.PriceAlerts) (items []models.PriceAlert, err error) {
+	q := squirrel.Select("*").From(models.PriceAlertsTable).OrderBy("pra_id")
+	if len(filter.IDs) != 0 {
+		q = q.Where(squirrel.Eq{"pra_id": filter.IDs})
+	}
+	if len(filter.UserIDs) != 0 {
+		q = q.Where(squirrel.Eq{"usr_id": filter.UserIDs})
+	}
+	err = m.find(&items, q)
+	return items, err
+}
+
+func (m DB) CreatePriceAlert(alert models.PriceAlert) (models.PriceAlert, error) {
+	q := squirrel.Insert(models.PriceAlertsTable).SetMap(map[string]interface{}{
+		"usr_id":        alert.UserID,
+		"pra_asset":     alert.Asset,
+		"pra_kind":      alert.Kind,
+		"pra_value":     alert.Value,
+		"pra_currency":  alert.Currency,
+		"pra_window":    alert.Window,
+		"pra_recurring": alert.Recurring,
+		"pra_armed":     alert.Armed,
+	})
+	id, err := m.insert(q)
+	alert.ID = id
+	return alert, err
+}
+
+func (m DB) UpdatePriceAlert(alert models.PriceAlert) error {
+	q := squirrel.Update(models.PriceAlertsTable).
+		Where(squirrel.Eq{"pra_id": alert.ID}).
+		SetMap(map[string]interface{}{
+			"pra_armed":        alert.Armed,
+			"pra_triggered_at": alert.TriggeredAt,
+		})
+	return m.update(q)
+}
+
+func (m DB) DeletePriceAlert(userID uint64, id uint64) error {
+	q := squirrel.Delete(models.PriceAlertsTable).
+		Where(squirrel.Eq{"usr_id": userID}).
+		Where(squirrel.Eq{"pra_id": id})
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(sql, args...)
+	return err
+}
@@ -0,0 +1,32 @@
+package mysql
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+)
+
+func (m DB) CreatePrices(items []models.Price) error {
+	if len(items) == 0 {
+		return nil
+	}
+	q := squirrel.Insert(models.PricesTable).Columns("prc_asset", "prc_value", "prc_created_at")
+	for _, item := range items {
+		q = q.Values(item.Asset, item.Value, item.CreatedAt)
+	}
+	_, err := m.insert(q)
+	return err
+}
+
+// GetPrices returns the prices ordered from the oldest.
+func (m DB) GetPrices(filter filters.Prices) (items []models.Price, err error) {
+	q := squirrel.Select("*").From(models.PricesTable).OrderBy("prc_created_at", "prc_id")
+	if len(filter.Assets) != 0 {
+		q = q.Where(squirrel.Eq{"prc_asset": filter.Assets})
+	}
+	if !filter.From.IsZero() {
+		q = q.Where(squirrel.GtOrEq{"prc_created_at": filter.From})
+	}
+	err = m.find(&items, q)
+	return items, err
+}
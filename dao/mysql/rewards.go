@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+)
+
+func (m DB) CreateReward(reward models.Reward) error {
+	q := squirrel.Insert(models.RewardsTable).SetMap(map[string]interface{}{
+		"rwd_address": reward.Address,
+		"rwd_node_id": reward.NodeID,
+		"rwd_value":   reward.Value,
+		"rwd_tx_hash": reward.TxHash,
+		"rwd_block":   reward.Block,
+	})
+	_, err := m.insert(q)
+	return err
+}
+
+func (m DB) GetAddressesRewards(filter filters.Rewards) (items []models.RewardTotal, err error) {
+	q := rewardsQuery("rwd_address", filter)
+	err = m.find(&items, q)
+	return items, err
+}
+
+func (m DB) GetNodesRewards(filter filters.Rewards) (items []models.RewardTotal, err error) {
+	q := rewardsQuery("rwd_node_id", filter).Where(squirrel.NotEq{"rwd_node_id": ""})
+	err = m.find(&items, q)
+	return items, err
+}
+
+func rewardsQuery(groupBy string, filter filters.Rewards) squirrel.SelectBuilder {
+	q := squirrel.Select(groupBy+" as rwt_key", "sum(rwd_value) as rwt_value").
+		From(models.RewardsTable).
+		GroupBy(groupBy)
+	if len(filter.Addresses) != 0 {
+		q = q.Where(squirrel.Eq{"rwd_address": filter.Addresses})
+	}
+	if len(filter.NodeIDs) != 0 {
+		q = q.Where(squirrel.Eq{"rwd_node_id": filter.NodeIDs})
+	}
+	if !filter.From.IsZero() {
+		q = q.Where(squirrel.GtOrEq{"rwd_created_at": filter.From})
+	}
+	return q
+}
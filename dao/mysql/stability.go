@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"time"
+)
+
+func (m DB) CreateStabilityIndexes(items []models.StabilityIndex) error {
+	if len(items) == 0 {
+		return nil
+	}
+	q := squirrel.Insert(models.StabilityIndexesTable).Columns("sti_node_id", "sti_value")
+	for _, item := range items {
+		q = q.Values(item.NodeID, item.Value)
+	}
+	_, err := m.insert(q)
+	return err
+}
+
+// GetLastStabilityIndexes returns the latest stored index of every node.
+func (m DB) GetLastStabilityIndexes() (items []models.StabilityIndex, err error) {
+	last := squirrel.Select("max(sti_id)").From(models.StabilityIndexesTable).GroupBy("sti_node_id")
+	sql, args, err := last.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	q := squirrel.Select("*").From(models.StabilityIndexesTable).Where("sti_id in ("+sql+")", args...)
+	err = m.find(&items, q)
+	return items, err
+}
+
+// DeleteStabilityIndexes deletes the indexes stored before the time, the latest index of every node is kept.
+func (m DB) DeleteStabilityIndexes(before time.Time) error {
+	// mysql does not allow to select from the deleted table directly, so the ids go through a derived table
+	q := squirrel.Delete(models.StabilityIndexesTable).
+		Where(squirrel.Lt{"sti_created_at": before}).
+		Where("sti_id not in (select id from (select max(sti_id) id from " + models.StabilityIndexesTable + " group by sti_node_id) last)")
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(sql, args...)
+	return err
+}
+
+func (m DB) GetStabilityAlerts() (items []models.StabilityAlert, err error) {
+	q := squirrel.Select("*").From(models.StabilityAlertsTable)
+	err = m.find(&items, q)
+	return items, err
+}
+
+func (m DB) CreateStabilityAlert(alert models.StabilityAlert) error {
+	q := squirrel.Insert(models.StabilityAlertsTable).SetMap(map[string]interface{}{
+		"usr_id":            alert.UserID,
+		"sta_node_id":       alert.NodeID,
+		"sta_recover_level": alert.RecoverLevel,
+	})
+	_, err := m.insert(q)
+	return err
+}
+
+func (m DB) DeleteStabilityAlert(userID uint64, nodeID string) error {
+	q := squirrel.Delete(models.StabilityAlertsTable).
+		Where(squirrel.Eq{"usr_id": userID}).
+		Where(squirrel.Eq{"sta_node_id": nodeID})
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(sql, args...)
+	return err
+}
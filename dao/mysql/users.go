@@ -14,20 +14,26 @@ func (m DB) GetUsers(filter filters.Users) (users []models.User, err error) {
 	if len(filter.TgIDs) != 0 {
 		q = q.Where(squirrel.Eq{"usr_tg_id": filter.TgIDs})
 	}
+	if len(filter.APITokens) != 0 {
+		q = q.Where(squirrel.Eq{"usr_api_token": filter.APITokens})
+	}
 	err = m.find(&users, q)
 	return users, err
 }
 
 func (m DB) CreateUser(user models.User) (models.User, error) {
 	q := squirrel.Insert(models.UsersTable).SetMap(map[string]interface{}{
-		"usr_tg_id":        user.TgID,
-		"usr_name":         user.Name,
-		"usr_lang":         user.Lang,
-		"usr_username":     user.Username,
-		"usr_mute":         user.Mute,
-		"usr_step":         user.Step,
-		"usr_min_threshold": user.MinThreshold,
-		"usr_max_threshold": user.MaxThreshold,
+		"usr_tg_id":           user.TgID,
+		"usr_name":            user.Name,
+		"usr_lang":            user.Lang,
+		"usr_currency":        user.Currency,
+		"usr_username":        user.Username,
+		"usr_mute":            user.Mute,
+		"usr_step":            user.Step,
+		"usr_min_threshold":   user.MinThreshold,
+		"usr_max_threshold":   user.MaxThreshold,
+		"usr_stability_level": user.StabilityLevel,
+		"usr_stability_drop":  user.StabilityDrop,
 	})
 	var err error
 	user.ID, err = m.insert(q)
@@ -36,11 +42,17 @@ func (m DB) CreateUser(user models.User) (models.User, error) {
 
 func (m DB) UpdateUser(user models.User) error {
 	q := squirrel.Update(models.UsersTable).SetMap(map[string]interface{}{
-		"usr_lang":         user.Lang,
-		"usr_mute":         user.Mute,
-		"usr_step":         user.Step,
-		"usr_min_threshold": user.MinThreshold,
-		"usr_max_threshold": user.MaxThreshold,
+		"usr_lang":            user.Lang,
+		"usr_currency":        user.Currency,
+		"usr_mute":            user.Mute,
+		"usr_step":            user.Step,
+		"usr_min_threshold":   user.MinThreshold,
+		"usr_max_threshold":   user.MaxThreshold,
+		"usr_webhook_secret":  user.WebhookSecret,
+		"usr_api_token":       user.APIToken,
+		"usr_whale_share":     user.WhaleShare,
+		"usr_stability_level": user.StabilityLevel,
+		"usr_stability_drop":  user.StabilityDrop,
 	}).Where(squirrel.Eq{"usr_id": user.ID})
 	return m.update(q)
 }
@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+)
+
+func (m DB) GetVotesSnapshots(filter filters.VotesSnapshots) (items []models.VoteSnapshot, err error) {
+	q := squirrel.Select("*").From(models.VotesSnapshotsTable)
+	if len(filter.NodeIDs) != 0 {
+		q = q.Where(squirrel.Eq{"vts_node_id": filter.NodeIDs})
+	}
+	if len(filter.Addresses) != 0 {
+		q = q.Where(squirrel.Eq{"vts_address": filter.Addresses})
+	}
+	err = m.find(&items, q)
+	return items, err
+}
+
+// ReplaceVotesSnapshot swaps the stored snapshot of the node votes with the new one in a single transaction.
+func (m DB) ReplaceVotesSnapshot(nodeID string, items []models.VoteSnapshot) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	sql, args, err := squirrel.Delete(models.VotesSnapshotsTable).Where(squirrel.Eq{"vts_node_id": nodeID}).ToSql()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if len(items) != 0 {
+		q := squirrel.Insert(models.VotesSnapshotsTable).Columns("vts_node_id", "vts_address", "vts_value")
+		for _, item := range items {
+			q = q.Values(nodeID, item.Address, item.Value)
+		}
+		sql, args, err = q.ToSql()
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec(sql, args...)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -10,4 +10,5 @@ require (
 	github.com/rubenv/sql-migrate v0.0.0-20200616145509-8d140a17f351
 	github.com/shopspring/decimal v1.2.0
 	github.com/technoweenie/multipartstreamer v1.0.1 // indirect
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 )
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/everstake/nebulas-tg-bot/config"
 	"github.com/everstake/nebulas-tg-bot/dao"
+	"github.com/everstake/nebulas-tg-bot/services/api"
 	"github.com/everstake/nebulas-tg-bot/services/bot"
 	"github.com/everstake/nebulas-tg-bot/services/modules"
 	"log"
@@ -13,21 +14,23 @@ import (
 func main() {
 	err := os.Setenv("TZ", "UTC")
 	if err != nil {
-		log.Fatal("os.Setenv (TZ): %s", err.Error())
+		log.Fatalf("os.Setenv (TZ): %s", err.Error())
 	}
 
 	cfg := config.GetConfig()
 	d, err := dao.NewDAO(cfg)
 	if err != nil {
-		log.Fatal("dao.NewDAO: %s", err.Error())
+		log.Fatalf("dao.NewDAO: %s", err.Error())
 	}
 
 	b := bot.NewBot(d, cfg)
 
-	g := modules.NewGroup(b)
+	a := api.NewAPI(cfg, d, b)
+
+	g := modules.NewGroup(b, a)
 	g.Run()
 
-	interrupt := make(chan os.Signal)
+	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, os.Kill)
 
 	<-interrupt
@@ -19,6 +19,12 @@ type AddressState struct {
 	NAX         decimal.Decimal `json:"nax"`
 	Alias       string          `json:"alias"`
 	Type        string          `json:"type"`
-	TotalVotes  decimal.Decimal `json:"total_votes"`
 	VotedAmount decimal.Decimal `json:"voted_amount"`
+	NodeID      string          `json:"node_id"`
+	// Functions, Args and Events are the filters of a contract subscription.
+	Functions string `json:"functions,omitempty"`
+	Args      string `json:"args,omitempty"`
+	Events    bool   `json:"events,omitempty"`
+	// Unavailable is set when the balances could not be read from the node.
+	Unavailable bool `json:"unavailable"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	GovernancePeriodsTable      = "governance_periods"
+	GovernanceParticipantsTable = "governance_participants"
+)
+
+type (
+	GovernancePeriod struct {
+		Number     uint64 `db:"gvp_number"`
+		StartBlock uint64 `db:"gvp_start_block"`
+		EndBlock   uint64 `db:"gvp_end_block"`
+		// RemindedBlock is the block of the last reminder sent, Finished is set once the results are announced
+		RemindedBlock uint64    `db:"gvp_reminded_block"`
+		Finished      bool      `db:"gvp_finished"`
+		CreatedAt     time.Time `db:"gvp_created_at"`
+	}
+	GovernanceParticipant struct {
+		PeriodNumber uint64    `db:"gvp_number"`
+		NodeID       string    `db:"gpt_node_id"`
+		GovManager   string    `db:"gpt_gov_manager"`
+		Participated bool      `db:"gpt_participated"`
+		Block        uint64    `db:"gpt_block"` // block of the first participation
+		CreatedAt    time.Time `db:"gpt_created_at"`
+	}
+)
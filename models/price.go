@@ -0,0 +1,16 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const PricesTable = "prices"
+
+// Price is an aggregated USDT price of the asset.
+type Price struct {
+	ID        uint64          `db:"prc_id"`
+	Asset     string          `db:"prc_asset"`
+	Value     decimal.Decimal `db:"prc_value"`
+	CreatedAt time.Time       `db:"prc_created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const PriceAlertsTable = "price_alerts"
+
+const (
+	PriceAlertAbove  = "above"
+	PriceAlertBelow  = "below"
+	PriceAlertChange = "change"
+)
+
+type PriceAlert struct {
+	ID     uint64          `db:"pra_id"`
+	UserID uint64          `db:"usr_id"`
+	Asset  string          `db:"pra_asset"`
+	Kind   string          `db:"pra_kind"`
+	Value  decimal.Decimal `db:"pra_value"`
+	// Currency of the above/below price.
+	Currency string `db:"pra_currency"`
+	// Window of the change alert in minutes.
+	Window    uint64 `db:"pra_window"`
+	Recurring bool   `db:"pra_recurring"`
+	// Armed is false while a recurring alert waits for the price to come back.
+	Armed       bool       `db:"pra_armed"`
+	TriggeredAt *time.Time `db:"pra_triggered_at"`
+	CreatedAt   time.Time  `db:"pra_created_at"`
+}
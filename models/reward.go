@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const RewardsTable = "rewards"
+
+type (
+	Reward struct {
+		ID        uint64          `db:"rwd_id"`
+		Address   string          `db:"rwd_address"`
+		NodeID    string          `db:"rwd_node_id"`
+		Value     decimal.Decimal `db:"rwd_value"`
+		TxHash    string          `db:"rwd_tx_hash"`
+		Block     uint64          `db:"rwd_block"`
+		CreatedAt time.Time       `db:"rwd_created_at"`
+	}
+	// RewardTotal is a sum of rewards grouped by address or node.
+	RewardTotal struct {
+		Key   string          `db:"rwt_key"`
+		Value decimal.Decimal `db:"rwt_value"`
+	}
+)
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	StabilityIndexesTable = "stability_indexes"
+	StabilityAlertsTable  = "stability_alerts"
+)
+
+type (
+	StabilityIndex struct {
+		ID        uint64    `db:"sti_id"`
+		NodeID    string    `db:"sti_node_id"`
+		Value     float64   `db:"sti_value"`
+		CreatedAt time.Time `db:"sti_created_at"`
+	}
+	// StabilityAlert is kept while the node stays below the user thresholds.
+	StabilityAlert struct {
+		UserID       uint64          `db:"usr_id"`
+		NodeID       string          `db:"sta_node_id"`
+		RecoverLevel decimal.Decimal `db:"sta_recover_level"`
+		CreatedAt    time.Time       `db:"sta_created_at"`
+	}
+)
@@ -8,14 +8,20 @@ import (
 const UsersTable = "users"
 
 type User struct {
-	ID          uint64          `db:"usr_id"`
-	TgID        int64           `db:"usr_tg_id"`
-	Lang        string          `db:"usr_lang"`
-	Username    string          `db:"usr_username"`
-	Name        string          `db:"usr_name"`
-	Mute        bool            `db:"usr_mute"`
-	Step        string          `db:"usr_step"`
-	MinThreshold decimal.Decimal `db:"usr_min_threshold"`
-	MaxThreshold decimal.Decimal `db:"usr_max_threshold"`
-	CreatedAt   time.Time       `db:"usr_created_at"`
+	ID             uint64          `db:"usr_id"`
+	TgID           int64           `db:"usr_tg_id"`
+	Lang           string          `db:"usr_lang"`
+	Currency       string          `db:"usr_currency"`
+	Username       string          `db:"usr_username"`
+	Name           string          `db:"usr_name"`
+	Mute           bool            `db:"usr_mute"`
+	Step           string          `db:"usr_step"`
+	MinThreshold   decimal.Decimal `db:"usr_min_threshold"`
+	MaxThreshold   decimal.Decimal `db:"usr_max_threshold"`
+	WebhookSecret  string          `db:"usr_webhook_secret"`
+	APIToken       string          `db:"usr_api_token"`
+	WhaleShare     decimal.Decimal `db:"usr_whale_share"`
+	StabilityLevel decimal.Decimal `db:"usr_stability_level"`
+	StabilityDrop  decimal.Decimal `db:"usr_stability_drop"`
+	CreatedAt      time.Time       `db:"usr_created_at"`
 }
@@ -6,12 +6,18 @@ const UserAddressesTable = "users_addresses"
 
 const AddressTypeValidator = "validator"
 const AddressTypeAccount = "account"
+const AddressTypeContract = "contract"
 
 type UserAddress struct {
 	UserID    uint64 `db:"usr_id"`
 	AddressID uint64 `db:"adr_id"`
 	Alias     string `db:"usa_alias"`
 	Type      string `db:"usa_type"`
+	Webhook   string `db:"usa_webhook"`
+	// Functions and Args are the comma separated filters of a contract subscription.
+	Functions string `db:"usa_functions"`
+	Args      string `db:"usa_args"`
+	Events    bool   `db:"usa_events"`
 }
 
 type UserAddressReport struct {
@@ -19,5 +25,9 @@ type UserAddressReport struct {
 	Address   string    `db:"address"`
 	Alias     string    `db:"alias"`
 	Type      string    `db:"type"`
+	Webhook   string    `db:"webhook"`
+	Functions string    `db:"functions"`
+	Args      string    `db:"args"`
+	Events    bool      `db:"events"`
 	CreatedAt time.Time `db:"created_at"`
 }
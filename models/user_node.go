@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+const UsersNodesTable = "users_nodes"
+
+type UserNode struct {
+	UserID    uint64    `db:"usr_id"`
+	NodeID    string    `db:"usn_node_id"`
+	Alias     string    `db:"usn_alias"`
+	Webhook   string    `db:"usn_webhook"`
+	CreatedAt time.Time `db:"usn_created_at"`
+}
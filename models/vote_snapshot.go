@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const VotesSnapshotsTable = "votes_snapshots"
+
+type VoteSnapshot struct {
+	NodeID    string          `db:"vts_node_id"`
+	Address   string          `db:"vts_address"`
+	Value     decimal.Decimal `db:"vts_value"`
+	CreatedAt time.Time       `db:"vts_created_at"`
+}
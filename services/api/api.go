@@ -0,0 +1,250 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/config"
+	"github.com/everstake/nebulas-tg-bot/dao"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/bot"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	subscriptionsPath = "/v1/subscriptions"
+	maxBodySize       = 1 << 20
+)
+
+type (
+	API struct {
+		cfg           config.API
+		dao           dao.DAO
+		subscriptions Subscriptions
+		server        *http.Server
+	}
+	Subscriptions interface {
+		Subscribe(user models.User, address string, alias string, addressType string) error
+		UpdateSubscription(user models.User, address string, alias string, addressType string) error
+		Unsubscribe(user models.User, address string) error
+	}
+	handler func(w http.ResponseWriter, r *http.Request, user models.User)
+
+	Subscription struct {
+		Address   string    `json:"address"`
+		Alias     string    `json:"alias"`
+		Type      string    `json:"type"`
+		CreatedAt time.Time `json:"created_at,omitempty"`
+	}
+	Result struct {
+		Address string `json:"address"`
+		Error   string `json:"error,omitempty"`
+	}
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+func NewAPI(cfg config.Config, d dao.DAO, subscriptions Subscriptions) *API {
+	return &API{
+		cfg:           cfg.API,
+		dao:           d,
+		subscriptions: subscriptions,
+	}
+}
+
+func (api *API) Run() error {
+	if api.cfg.Listen == "" {
+		log.Info("API: listen address is not set, API is disabled")
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(subscriptionsPath, api.auth(api.subscriptionsHandler))
+	mux.HandleFunc(subscriptionsPath+"/", api.auth(api.subscriptionHandler))
+	api.server = &http.Server{
+		Addr:         api.cfg.Listen,
+		Handler:      mux,
+		ReadTimeout:  time.Second * 30,
+		WriteTimeout: time.Minute,
+	}
+	log.Info("API: listening on %s", api.cfg.Listen)
+	err := api.server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server.ListenAndServe: %s", err.Error())
+	}
+	return nil
+}
+
+func (api *API) Stop() error {
+	if api.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	return api.server.Shutdown(ctx)
+}
+
+func (api *API) Title() string {
+	return "REST API"
+}
+
+func (api *API) auth(h handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if token == "" {
+			jsonError(w, http.StatusUnauthorized, "missing token")
+			return
+		}
+		users, err := api.dao.GetUsers(filters.Users{APITokens: []string{token}})
+		if err != nil {
+			log.Error("API: dao.GetUsers: %s", err.Error())
+			jsonError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if len(users) == 0 {
+			jsonError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		h(w, r, users[0])
+	}
+}
+
+// subscriptionsHandler serves the collection: GET lists subscriptions, POST adds one subscription or an array of them.
+func (api *API) subscriptionsHandler(w http.ResponseWriter, r *http.Request, user models.User) {
+	switch r.Method {
+	case http.MethodGet:
+		items, err := api.dao.GetUsersAddressReports(filters.UsersAddresses{UserID: []uint64{user.ID}})
+		if err != nil {
+			log.Error("API: dao.GetUsersAddressReports: %s", err.Error())
+			jsonError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		list := make([]Subscription, 0, len(items))
+		for _, item := range items {
+			list = append(list, Subscription{
+				Address:   item.Address,
+				Alias:     item.Alias,
+				Type:      item.Type,
+				CreatedAt: item.CreatedAt,
+			})
+		}
+		jsonResponse(w, http.StatusOK, list)
+	case http.MethodPost:
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, "can`t read body")
+			return
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) != 0 && data[0] == '[' {
+			var list []Subscription
+			err = json.Unmarshal(data, &list)
+			if err != nil {
+				jsonError(w, http.StatusBadRequest, "invalid json")
+				return
+			}
+			results := make([]Result, 0, len(list))
+			for _, s := range list {
+				result := Result{Address: s.Address}
+				_, msg := api.subscribe(user, s)
+				result.Error = msg
+				results = append(results, result)
+			}
+			jsonResponse(w, http.StatusOK, results)
+			return
+		}
+		var s Subscription
+		err = json.Unmarshal(data, &s)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		status, msg := api.subscribe(user, s)
+		if msg != "" {
+			jsonError(w, status, msg)
+			return
+		}
+		jsonResponse(w, http.StatusCreated, s)
+	default:
+		jsonError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// subscriptionHandler serves a single subscription addressed as /v1/subscriptions/{address}.
+func (api *API) subscriptionHandler(w http.ResponseWriter, r *http.Request, user models.User) {
+	address := strings.Trim(strings.TrimPrefix(r.URL.Path, subscriptionsPath), "/")
+	if address == "" {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut, http.MethodPatch:
+		var s Subscription
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&s)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		err = api.subscriptions.UpdateSubscription(user, address, s.Alias, s.Type)
+		if err != nil {
+			status, msg := errorStatus(err)
+			jsonError(w, status, msg)
+			return
+		}
+		jsonResponse(w, http.StatusOK, Result{Address: address})
+	case http.MethodDelete:
+		err := api.subscriptions.Unsubscribe(user, address)
+		if err != nil {
+			status, msg := errorStatus(err)
+			jsonError(w, status, msg)
+			return
+		}
+		jsonResponse(w, http.StatusOK, Result{Address: address})
+	default:
+		jsonError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (api *API) subscribe(user models.User, s Subscription) (status int, msg string) {
+	if s.Type == "" {
+		s.Type = models.AddressTypeAccount
+	}
+	err := api.subscriptions.Subscribe(user, strings.TrimSpace(s.Address), s.Alias, s.Type)
+	if err != nil {
+		return errorStatus(err)
+	}
+	return http.StatusCreated, ""
+}
+
+func errorStatus(err error) (status int, msg string) {
+	switch {
+	case err == bot.ErrInvalidAddressType, err == bot.ErrNotValidatorAccount:
+		return http.StatusBadRequest, err.Error()
+	case node.IsAddressError(err):
+		return http.StatusBadRequest, err.Error()
+	case err.Error() == derrors.ErrDuplicate:
+		return http.StatusConflict, "already subscribed"
+	case err.Error() == derrors.ErrNotFound:
+		return http.StatusNotFound, "not found"
+	}
+	log.Error("API: %s", err.Error())
+	return http.StatusInternalServerError, "internal error"
+}
+
+func jsonResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func jsonError(w http.ResponseWriter, status int, msg string) {
+	jsonResponse(w, status, errorResponse{Error: msg})
+}
@@ -1,11 +1,197 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
 	"github.com/everstake/nebulas-tg-bot/dao/filters"
 	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
 )
 
+const maxAliasLength = 100
+
+var (
+	ErrInvalidAddressType  = errors.New("invalid address type")
+	ErrNotValidatorAccount = errors.New("not a validator account")
+)
+
+var addressErrorKeys = map[node.AddressError]string{
+	node.ErrAddressLength:   "t.wrong_address_length",
+	node.ErrAddressEncoding: "t.wrong_address_encoding",
+	node.ErrAddressPadding:  "t.wrong_address_prefix",
+	node.ErrAddressType:     "t.wrong_address_type",
+	node.ErrAddressChecksum: "t.wrong_address_checksum",
+}
+
+// addressErrorText explains to the user why the address was rejected.
+func (bot *Bot) addressErrorText(err error, lang string) string {
+	key := "t.wrong_address"
+	if addrErr, ok := err.(node.AddressError); ok {
+		if k, ok := addressErrorKeys[addrErr]; ok {
+			key = k
+		}
+	}
+	return bot.dictionary.Get(key, lang)
+}
+
+func validAddressType(addressType string) bool {
+	return addressType == models.AddressTypeAccount ||
+		addressType == models.AddressTypeValidator ||
+		addressType == models.AddressTypeContract
+}
+
+// validatorNode resolves the validator type: the address is watched as an account and the node it belongs to
+// is followed by the node ID, so the node alerts survive changes of the node accounts.
+func (bot *Bot) validatorNode(address string, addressType string) (nodeID string, accountType string, err error) {
+	if addressType != models.AddressTypeValidator {
+		return "", addressType, nil
+	}
+	bot.mu.RLock()
+	nodeID = bot.nodeAccounts[address]
+	bot.mu.RUnlock()
+	if nodeID == "" {
+		return "", addressType, ErrNotValidatorAccount
+	}
+	return nodeID, models.AddressTypeAccount, nil
+}
+
+// Subscribe adds the address to the user subscriptions, the address is created if it is not known yet.
+// The validator type subscribes to the address as an account and follows its node.
+func (bot *Bot) Subscribe(user models.User, address string, alias string, addressType string) error {
+	_, err := node.ParseAddress(address)
+	if err != nil {
+		return err
+	}
+	if !validAddressType(addressType) {
+		return ErrInvalidAddressType
+	}
+	nodeID, addressType, err := bot.validatorNode(address, addressType)
+	if err != nil {
+		return err
+	}
+	addresses, err := bot.dao.GetAddresses(filters.Addresses{Addresses: []string{address}})
+	if err != nil {
+		return fmt.Errorf("dao.GetAddresses: %s", err.Error())
+	}
+	var addressModel models.Address
+	if len(addresses) == 0 {
+		addressModel, err = bot.dao.CreateAddress(models.Address{
+			Address: address,
+		})
+		if err != nil {
+			return fmt.Errorf("dao.CreateAddress: %s", err.Error())
+		}
+	} else {
+		addressModel = addresses[0]
+	}
+	if len(alias) > maxAliasLength {
+		alias = alias[:maxAliasLength]
+	}
+	err = bot.dao.CreateUserAddress(models.UserAddress{
+		UserID:    user.ID,
+		AddressID: addressModel.ID,
+		Alias:     alias,
+		Type:      addressType,
+	})
+	if err != nil {
+		return err
+	}
+	bot.addAccountAddress(user, addressModel)
+	bot.mu.Lock()
+	bot.setAlias(user.ID, address, alias)
+	bot.mu.Unlock()
+	if addressType == models.AddressTypeContract {
+		bot.addContractAddress(user, addressModel, contractFilter{})
+	}
+	if nodeID != "" {
+		err = bot.followNode(user, nodeID)
+		if err != nil && err.Error() != derrors.ErrDuplicate {
+			return fmt.Errorf("followNode: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// UpdateSubscription changes alias and type of the existing subscription, empty values are left untouched.
+func (bot *Bot) UpdateSubscription(user models.User, address string, alias string, addressType string) error {
+	if addressType != "" && !validAddressType(addressType) {
+		return ErrInvalidAddressType
+	}
+	nodeID, addressType, err := bot.validatorNode(address, addressType)
+	if err != nil {
+		return err
+	}
+	addressModel, userAddress, err := bot.findSubscription(user, address)
+	if err != nil {
+		return err
+	}
+	if alias != "" {
+		if len(alias) > maxAliasLength {
+			alias = alias[:maxAliasLength]
+		}
+		userAddress.Alias = alias
+	}
+	if addressType != "" {
+		userAddress.Type = addressType
+	}
+	err = bot.dao.UpdateUserAddress(userAddress)
+	if err != nil {
+		return fmt.Errorf("dao.UpdateUserAddress: %s", err.Error())
+	}
+	bot.mu.Lock()
+	bot.setAlias(user.ID, addressModel.Address, userAddress.Alias)
+	bot.mu.Unlock()
+	if userAddress.Type == models.AddressTypeContract {
+		bot.addContractAddress(user, addressModel, newContractFilter(userAddress.Functions, userAddress.Args, userAddress.Events))
+	} else {
+		bot.mu.Lock()
+		delete(bot.contracts[addressModel.Address], user.ID)
+		bot.mu.Unlock()
+	}
+	if nodeID != "" {
+		err = bot.followNode(user, nodeID)
+		if err != nil && err.Error() != derrors.ErrDuplicate {
+			return fmt.Errorf("followNode: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (bot *Bot) Unsubscribe(user models.User, address string) error {
+	addressModel, _, err := bot.findSubscription(user, address)
+	if err != nil {
+		return err
+	}
+	err = bot.dao.DeleteUserAddress(user.ID, addressModel.ID)
+	if err != nil {
+		return fmt.Errorf("dao.DeleteUserAddress: %s", err.Error())
+	}
+	bot.removeAddress(user, addressModel)
+	return nil
+}
+
+func (bot *Bot) findSubscription(user models.User, address string) (addressModel models.Address, userAddress models.UserAddress, err error) {
+	addresses, err := bot.dao.GetAddresses(filters.Addresses{Addresses: []string{address}})
+	if err != nil {
+		return addressModel, userAddress, fmt.Errorf("dao.GetAddresses: %s", err.Error())
+	}
+	if len(addresses) == 0 {
+		return addressModel, userAddress, errors.New(derrors.ErrNotFound)
+	}
+	usersAddresses, err := bot.dao.GetUsersAddresses(filters.UsersAddresses{
+		UserID:      []uint64{user.ID},
+		AddressesID: []uint64{addresses[0].ID},
+	})
+	if err != nil {
+		return addressModel, userAddress, fmt.Errorf("dao.GetUsersAddresses: %s", err.Error())
+	}
+	if len(usersAddresses) == 0 {
+		return addressModel, userAddress, errors.New(derrors.ErrNotFound)
+	}
+	return addresses[0], usersAddresses[0], nil
+}
+
 func (bot *Bot) setAddresses() error {
 	addresses, err := bot.dao.GetAddresses(filters.Addresses{})
 	if err != nil {
@@ -33,13 +219,22 @@ func (bot *Bot) setAddresses() error {
 			bot.addresses[address.Address] = make(map[uint64]struct{})
 		}
 		bot.addresses[address.Address][ua.UserID] = struct{}{}
+		bot.setAlias(ua.UserID, address.Address, ua.Alias)
 
-		if ua.Type == models.AddressTypeValidator {
-			_, ok = bot.validators[address.Address]
+		if ua.Type == models.AddressTypeContract {
+			_, ok = bot.contracts[address.Address]
 			if !ok {
-				bot.validators[address.Address] = make(map[uint64]struct{})
+				bot.contracts[address.Address] = make(map[uint64]contractFilter)
 			}
-			bot.validators[address.Address][ua.UserID] = struct{}{}
+			bot.contracts[address.Address][ua.UserID] = newContractFilter(ua.Functions, ua.Args, ua.Events)
+		}
+
+		if ua.Webhook != "" {
+			_, ok = bot.webhookURLs[address.Address]
+			if !ok {
+				bot.webhookURLs[address.Address] = make(map[uint64]string)
+			}
+			bot.webhookURLs[address.Address][ua.UserID] = ua.Webhook
 		}
 	}
 	return nil
@@ -51,29 +246,36 @@ func (bot *Bot) updateUserSettings(user models.User) {
 	bot.mu.Unlock()
 }
 
-func (bot *Bot) addValidatorAddress(user models.User, address models.Address) {
+func (bot *Bot) addAccountAddress(user models.User, address models.Address) {
 	bot.mu.Lock()
-	_, ok := bot.validators[address.Address]
+	_, ok := bot.addresses[address.Address]
 	if !ok {
-		bot.validators[address.Address] = make(map[uint64]struct{})
+		bot.addresses[address.Address] = make(map[uint64]struct{})
 	}
-	bot.validators[address.Address][user.ID] = struct{}{}
+	bot.addresses[address.Address][user.ID] = struct{}{}
 	bot.mu.Unlock()
 }
 
-func (bot *Bot) addAccountAddress(user models.User, address models.Address) {
+func (bot *Bot) setWebhook(user models.User, address models.Address, url string) {
 	bot.mu.Lock()
-	_, ok := bot.addresses[address.Address]
+	defer bot.mu.Unlock()
+	if url == "" {
+		delete(bot.webhookURLs[address.Address], user.ID)
+		return
+	}
+	_, ok := bot.webhookURLs[address.Address]
 	if !ok {
-		bot.addresses[address.Address] = make(map[uint64]struct{})
+		bot.webhookURLs[address.Address] = make(map[uint64]string)
 	}
-	bot.addresses[address.Address][user.ID] = struct{}{}
-	bot.mu.Unlock()
+	bot.webhookURLs[address.Address][user.ID] = url
 }
 
 func (bot *Bot) removeAddress(user models.User, address models.Address) {
 	bot.mu.Lock()
 	defer bot.mu.Unlock()
+	delete(bot.webhookURLs[address.Address], user.ID)
+	delete(bot.contracts[address.Address], user.ID)
+	delete(bot.aliases[user.ID], address.Address)
 	_, ok := bot.addresses[address.Address]
 	if !ok {
 		return
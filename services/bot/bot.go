@@ -5,16 +5,20 @@ import (
 	"fmt"
 	"github.com/everstake/nebulas-tg-bot/config"
 	"github.com/everstake/nebulas-tg-bot/dao"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
 	"github.com/everstake/nebulas-tg-bot/dao/filters"
 	"github.com/everstake/nebulas-tg-bot/log"
 	"github.com/everstake/nebulas-tg-bot/models"
 	"github.com/everstake/nebulas-tg-bot/services/market"
 	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/shopspring/decimal"
 	"io/ioutil"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const StakingContract = "n214bLrE3nREcpRewHXF7qRDWCcaxRSiUdw"
@@ -27,28 +31,61 @@ type (
 		node                 NodeAPI
 		market               marketAPI
 		routes               map[string]Route
+		commands             map[string]Command
 		dictionary           models.Dictionary
 		cachedItems          map[uint64]map[string]interface{} // [userID][key]
 		mu                   *sync.RWMutex
-		addresses            map[string]map[uint64]struct{} // [address][userID]
-		validators           map[string]map[uint64]struct{} // [address][userID]
+		addresses            map[string]map[uint64]struct{}       // [address][userID]
+		contracts            map[string]map[uint64]contractFilter // [address][userID]
 		users                map[uint64]models.User
 		nodes                map[string]node.ValidatorNode
 		lastStabilityIndexes map[string]float64
+		webhook              webhookAPI
+		webhookURLs          map[string]map[uint64]string          // [address][userID]
+		nodeFollowers        map[string]map[uint64]struct{}        // [nodeID][userID]
+		nodeWebhooks         map[string]map[uint64]string          // [nodeID][userID]
+		nodeAccounts         map[string]string                     // [address]nodeID
+		stabilityAlerts      map[string]map[uint64]decimal.Decimal // [nodeID][userID]recover level
+		offlineSince         map[string]time.Time                  // [nodeID]
+		offlineNotified      map[string]struct{}                   // [nodeID]
+		governance           governanceState
+		production           productionState
+		pending              map[string]pendingTx       // [tx hash]
+		priceHistory         map[string][]pricePoint    // [asset]
+		pricesStoredAt       map[string]time.Time       // [asset]
+		stateCache           map[string]addressBalances // [address]
+		stateTouched         map[string]uint64          // [address] block of the last parsed tx
+		parsedHeight         uint64
+		labels               map[string]string            // [address]
+		aliases              map[uint64]map[string]string // [userID][address]
+		stream               *node.Stream
+		newBlock             chan struct{}
 	}
 	marketAPI interface {
 		GetNASPrice() decimal.Decimal
 		GetNAXPrice() decimal.Decimal
+		GetPrice(asset string) decimal.Decimal
+		LastUpdated(asset string) time.Time
+		Convert(value decimal.Decimal, currency string) (result decimal.Decimal, ok bool)
+		OnUpdate(listener market.Listener)
+		Run()
+	}
+	webhookAPI interface {
+		Send(url string, secret string, event webhook.Event)
 		Run()
 	}
 	NodeAPI interface {
 		GetAccountState(address string) (state node.AccountState, err error)
 		GetBlock(height uint64) (block node.Block, err error)
+		GetDynasty(height uint64) (dynasty node.Dynasty, err error)
+		GetNebState() (state node.NebState, err error)
+		Subscribe(topics ...string) *node.Stream
 		GetLatestIrreversibleBlock() (block node.Block, err error)
 		GetNAXBalance(address string) (result decimal.Decimal, err error)
 		GetNodesList() (list []node.ValidatorNode, err error)
 		GetNodeVotesList(nodeID string) (list []node.Vote, err error)
 		GetVotedNAX(address string) (amount decimal.Decimal, err error)
+		GetEventsByHash(hash string) (events []node.Event, err error)
 	}
 )
 
@@ -61,10 +98,25 @@ func NewBot(d dao.DAO, cfg config.Config) *Bot {
 		node:                 node.NewAPI(cfg.Node),
 		mu:                   &sync.RWMutex{},
 		addresses:            make(map[string]map[uint64]struct{}),
-		validators:           make(map[string]map[uint64]struct{}),
+		contracts:            make(map[string]map[uint64]contractFilter),
 		users:                make(map[uint64]models.User),
 		nodes:                make(map[string]node.ValidatorNode),
 		lastStabilityIndexes: make(map[string]float64),
+		webhook:              webhook.NewSender(),
+		webhookURLs:          make(map[string]map[uint64]string),
+		nodeFollowers:        make(map[string]map[uint64]struct{}),
+		nodeWebhooks:         make(map[string]map[uint64]string),
+		nodeAccounts:         make(map[string]string),
+		stabilityAlerts:      make(map[string]map[uint64]decimal.Decimal),
+		offlineSince:         make(map[string]time.Time),
+		offlineNotified:      make(map[string]struct{}),
+		pending:              make(map[string]pendingTx),
+		priceHistory:         make(map[string][]pricePoint),
+		pricesStoredAt:       make(map[string]time.Time),
+		stateCache:           make(map[string]addressBalances),
+		stateTouched:         make(map[string]uint64),
+		aliases:              make(map[uint64]map[string]string),
+		newBlock:             make(chan struct{}, 1),
 	}
 }
 
@@ -84,20 +136,62 @@ func (bot *Bot) Run() (err error) {
 		return fmt.Errorf("json.Unmarshal: %s", err.Error())
 	}
 
+	err = bot.setNodes()
+	if err != nil {
+		return fmt.Errorf("setNodes: %s", err.Error())
+	}
+
+	err = bot.convertValidatorSubscriptions()
+	if err != nil {
+		return fmt.Errorf("convertValidatorSubscriptions: %s", err.Error())
+	}
+
 	err = bot.setAddresses()
 	if err != nil {
 		return fmt.Errorf("setAddresses: %s", err.Error())
 	}
 
-	err = bot.setNodes()
+	err = bot.setNodeFollowers()
 	if err != nil {
-		return fmt.Errorf("setNodes: %s", err.Error())
+		return fmt.Errorf("setNodeFollowers: %s", err.Error())
+	}
+
+	err = bot.setStabilityIndexes()
+	if err != nil {
+		return fmt.Errorf("setStabilityIndexes: %s", err.Error())
 	}
 
+	err = bot.setPriceHistory()
+	if err != nil {
+		return fmt.Errorf("setPriceHistory: %s", err.Error())
+	}
+
+	err = bot.setLabels()
+	if err != nil {
+		return fmt.Errorf("setLabels: %s", err.Error())
+	}
+
+	if len(bot.cfg.Rewards.Sources) == 0 {
+		log.Warn("Bot: rewards.sources is empty, only the distributions of the staking contract are recorded as rewards")
+	}
+	if !bot.participationTracked() {
+		log.Warn("Bot: governance.contract is not set, governance participation is not tracked")
+	}
+
+	bot.market.OnUpdate(bot.storePrices)
+	bot.market.OnUpdate(bot.checkPriceAlerts)
 	go bot.market.Run()
+	go bot.webhook.Run()
+	bot.stream = bot.node.Subscribe(node.TopicLatestIrreversibleBlock)
+	go bot.stream.Run()
+	go bot.listenStream()
 	go bot.Parsing()
+	if bot.cfg.Pending.Enabled {
+		go bot.watchPending()
+	}
 
 	bot.SetRoutes()
+	bot.SetCommands()
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
@@ -134,8 +228,21 @@ func (bot *Bot) handleUpdate(update tgbotapi.Update) error {
 	if err != nil {
 		return fmt.Errorf("findOrCreateUser: %s", err.Error())
 	}
-	if update.Message.Text == "/start" {
-		_ = bot.openRoute(RouteStart, user)
+	if update.Message.IsCommand() {
+		command, ok := bot.commands[update.Message.Command()]
+		if ok {
+			err = command(update, user)
+			if err != nil {
+				return fmt.Errorf("command(%s): %s", update.Message.Command(), err.Error())
+			}
+			return nil
+		}
+	}
+	if update.Message.Document != nil {
+		err = bot.importSubscriptions(update, user)
+		if err != nil {
+			return fmt.Errorf("importSubscriptions: %s", err.Error())
+		}
 		return nil
 	}
 	route, ok := bot.routes[user.Step]
@@ -171,22 +278,146 @@ func (bot *Bot) handleActions(update tgbotapi.Update) error {
 	}
 	user := users[0]
 	query := update.CallbackQuery.Data
-	parts := strings.Split(query, "_")
+	parts := strings.SplitN(query, "_", 2)
 	switch parts[0] {
 	case "delete":
 		if len(parts) == 1 {
 			return nil
 		}
-		addresses, err := bot.dao.GetAddresses(filters.Addresses{Addresses: []string{parts[1]}})
+		err = bot.Unsubscribe(user, parts[1])
+		if err != nil {
+			if err.Error() == derrors.ErrNotFound {
+				return nil
+			}
+			return fmt.Errorf("Unsubscribe: %s", err.Error())
+		}
+		_, err = bot.api.DeleteMessage(tgbotapi.DeleteMessageConfig{
+			ChatID:    user.TgID,
+			MessageID: update.CallbackQuery.Message.MessageID,
+		})
+		if err != nil {
+			return fmt.Errorf("api.DeleteMessage: %s", err.Error())
+		}
+	case "webhook":
+		if len(parts) == 1 {
+			return nil
+		}
+		bot.SetCachedItem(user.ID, "address", parts[1])
+		bot.SetCachedItem(user.ID, "webhook_node", "")
+		err = bot.openRoute(RouteWebhook, user)
+		if err != nil {
+			return fmt.Errorf("openRoute: %s", err.Error())
+		}
+	case "nodewebhook":
+		if len(parts) == 1 {
+			return nil
+		}
+		bot.SetCachedItem(user.ID, "webhook_node", parts[1])
+		err = bot.openRoute(RouteWebhook, user)
+		if err != nil {
+			return fmt.Errorf("openRoute: %s", err.Error())
+		}
+	case "nodes":
+		if len(parts) == 1 {
+			return nil
+		}
+		page, _ := strconv.Atoi(parts[1])
+		query, _ := bot.GetCachedItem(user.ID, "node_query")
+		q, _ := query.(string)
+		err = bot.sendNodePicker(user, q, page, update.CallbackQuery.Message.MessageID)
+		if err != nil {
+			return fmt.Errorf("sendNodePicker: %s", err.Error())
+		}
+	case "follow":
+		if len(parts) == 1 {
+			return nil
+		}
+		text := fmt.Sprintf(bot.dictionary.Get("t.node_followed", user.Lang), parts[1])
+		err = bot.followNode(user, parts[1])
+		if err != nil {
+			if err.Error() != derrors.ErrDuplicate {
+				return fmt.Errorf("followNode: %s", err.Error())
+			}
+			text = bot.dictionary.Get("t.node_already_followed", user.Lang)
+		}
+		err = bot.sendText(user, text)
+		if err != nil {
+			return fmt.Errorf("sendText: %s", err.Error())
+		}
+	case "vals":
+		if len(parts) == 1 {
+			return nil
+		}
+		err = bot.handleValidatorsPage(user, parts[1], update.CallbackQuery.Message.MessageID)
+		if err != nil {
+			return fmt.Errorf("handleValidatorsPage: %s", err.Error())
+		}
+	case "val":
+		if len(parts) == 1 {
+			return nil
+		}
+		err = bot.sendValidatorCard(user, parts[1])
+		if err != nil {
+			return fmt.Errorf("sendValidatorCard: %s", err.Error())
+		}
+	case "voters":
+		if len(parts) == 1 {
+			return nil
+		}
+		err = bot.sendVoters(user, parts[1])
+		if err != nil {
+			return fmt.Errorf("sendVoters: %s", err.Error())
+		}
+	case "palert":
+		if len(parts) == 1 {
+			return nil
+		}
+		id, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil
+		}
+		err = bot.dao.DeletePriceAlert(user.ID, id)
+		if err != nil {
+			return fmt.Errorf("dao.DeletePriceAlert: %s", err.Error())
+		}
+		err = bot.sendPriceAlerts(user, update.CallbackQuery.Message.MessageID)
+		if err != nil {
+			return fmt.Errorf("sendPriceAlerts: %s", err.Error())
+		}
+	case "subs":
+		if len(parts) == 1 {
+			return nil
+		}
+		page, _ := strconv.Atoi(parts[1])
+		states, err := bot.getSubscriptions(user)
 		if err != nil {
-			return fmt.Errorf("dao.GetAddresses: %s", err.Error())
+			return fmt.Errorf("getSubscriptions: %s", err.Error())
 		}
-		if len(addresses) == 0 {
+		err = bot.sendSubscriptionsPage(user, states, page)
+		if err != nil {
+			return fmt.Errorf("sendSubscriptionsPage: %s", err.Error())
+		}
+	case "cfilter":
+		if len(parts) == 1 {
+			return nil
+		}
+		bot.SetCachedItem(user.ID, "address", parts[1])
+		err = bot.openRoute(RouteContractFilter, user)
+		if err != nil {
+			return fmt.Errorf("openRoute: %s", err.Error())
+		}
+	case "palertadd":
+		err = bot.openRoute(RouteAddPriceAlert, user)
+		if err != nil {
+			return fmt.Errorf("openRoute: %s", err.Error())
+		}
+	case "unfollow":
+		if len(parts) == 1 {
 			return nil
 		}
-		err = bot.dao.DeleteUserAddress(user.ID, addresses[0].ID)
+		err = bot.unfollowNode(user, parts[1])
 		if err != nil {
-			return fmt.Errorf("dao.GetAddresses: %s", err.Error())
+			return fmt.Errorf("unfollowNode: %s", err.Error())
 		}
 		_, err = bot.api.DeleteMessage(tgbotapi.DeleteMessageConfig{
 			ChatID:    user.TgID,
@@ -195,7 +426,6 @@ func (bot *Bot) handleActions(update tgbotapi.Update) error {
 		if err != nil {
 			return fmt.Errorf("api.DeleteMessage: %s", err.Error())
 		}
-		bot.removeAddress(user, addresses[0])
 	}
 	return nil
 }
@@ -208,11 +438,14 @@ func (bot *Bot) findOrCreateUser(update tgbotapi.Update) (user models.User, err
 	}
 	if len(users) == 0 {
 		user, err = bot.dao.CreateUser(models.User{
-			TgID:        tgID,
-			Name:        update.Message.Chat.FirstName + " " + update.Message.Chat.LastName,
-			Username:    update.Message.Chat.UserName,
-			Lang:        "en",
-			MaxThreshold: decimal.NewFromFloat(99999999999),
+			TgID:           tgID,
+			Name:           update.Message.Chat.FirstName + " " + update.Message.Chat.LastName,
+			Username:       update.Message.Chat.UserName,
+			Lang:           "en",
+			Currency:       market.CurrencyUSD,
+			MaxThreshold:   decimal.NewFromFloat(99999999999),
+			StabilityLevel: defaultStabilityLevel,
+			StabilityDrop:  defaultStabilityDrop,
 		})
 		if err != nil {
 			return user, fmt.Errorf("dao.CreateUser: %s", err.Error())
@@ -234,9 +467,12 @@ func (bot *Bot) updateUser(update tgbotapi.Update) (user models.User, err error)
 	}
 	if len(users) == 0 {
 		user, err = bot.dao.CreateUser(models.User{
-			TgID:     tgID,
-			Name:     update.Message.Chat.FirstName + " " + update.Message.Chat.LastName,
-			Username: update.Message.Chat.UserName,
+			TgID:           tgID,
+			Name:           update.Message.Chat.FirstName + " " + update.Message.Chat.LastName,
+			Username:       update.Message.Chat.UserName,
+			Currency:       market.CurrencyUSD,
+			StabilityLevel: defaultStabilityLevel,
+			StabilityDrop:  defaultStabilityDrop,
 		})
 		if err != nil {
 			return user, fmt.Errorf("dao.CreateUser: %s", err.Error())
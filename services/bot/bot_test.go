@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/config"
+	"github.com/everstake/nebulas-tg-bot/dao"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+)
+
+// testDAO records the writes the tests look at, the other methods are not expected to be called.
+type testDAO struct {
+	dao.DAO
+	participants []models.GovernanceParticipant
+	rewards      []models.Reward
+}
+
+// testNode returns the events of the transactions, the other methods are not expected to be called.
+type testNode struct {
+	NodeAPI
+	events map[string][]node.Event // [tx hash]
+}
+
+func (n *testNode) GetEventsByHash(hash string) ([]node.Event, error) {
+	return n.events[hash], nil
+}
+
+func (d *testDAO) CreateReward(reward models.Reward) error {
+	d.rewards = append(d.rewards, reward)
+	return nil
+}
+
+func (d *testDAO) GetAddressesRewards(filter filters.Rewards) ([]models.RewardTotal, error) {
+	return nil, nil
+}
+
+func (d *testDAO) UpdateGovernanceParticipant(item models.GovernanceParticipant) error {
+	d.participants = append(d.participants, item)
+	return nil
+}
+
+func newTestBot(cfg config.Config) (*Bot, *testDAO) {
+	d := &testDAO{}
+	return NewBot(d, cfg), d
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"strings"
+)
+
+const (
+	CommandStart      = "start"
+	CommandAPIToken   = "apitoken"
+	CommandExport     = "export"
+	CommandValidators = "validators"
+	CommandProduction = "production"
+	CommandAlerts     = "alerts"
+	CommandPrice      = "price"
+)
+
+type Command func(update tgbotapi.Update, user models.User) error
+
+func (bot *Bot) SetCommands() {
+	bot.commands = map[string]Command{
+		CommandStart: func(update tgbotapi.Update, user models.User) error {
+			_ = bot.openRoute(RouteStart, user)
+			return nil
+		},
+		CommandAPIToken: func(update tgbotapi.Update, user models.User) error {
+			b := make([]byte, 32)
+			_, err := rand.Read(b)
+			if err != nil {
+				return fmt.Errorf("rand.Read: %s", err.Error())
+			}
+			user.APIToken = hex.EncodeToString(b)
+			err = bot.dao.UpdateUser(user)
+			if err != nil {
+				return fmt.Errorf("dao.UpdateUser: %s", err.Error())
+			}
+			bot.updateUserSettings(user)
+			msg := tgbotapi.NewMessage(user.TgID, fmt.Sprintf(bot.dictionary.Get("t.api_token", user.Lang), user.APIToken))
+			msg.ParseMode = tgbotapi.ModeMarkdown
+			_, err = bot.api.Send(msg)
+			if err != nil {
+				return fmt.Errorf("api.Send: %s", err.Error())
+			}
+			return nil
+		},
+		CommandExport: func(update tgbotapi.Update, user models.User) error {
+			format := strings.ToLower(strings.TrimSpace(update.Message.CommandArguments()))
+			err := bot.exportSubscriptions(user, format)
+			if err != nil {
+				return fmt.Errorf("exportSubscriptions: %s", err.Error())
+			}
+			return nil
+		},
+		CommandValidators: func(update tgbotapi.Update, user models.User) error {
+			err := bot.sendValidators(user, sortByVotes, 0, 0)
+			if err != nil {
+				return fmt.Errorf("sendValidators: %s", err.Error())
+			}
+			return nil
+		},
+		CommandProduction: func(update tgbotapi.Update, user models.User) error {
+			query := strings.TrimSpace(update.Message.CommandArguments())
+			if query == "" {
+				return bot.sendText(user, bot.dictionary.Get("t.production_usage", user.Lang))
+			}
+			err := bot.sendProduction(user, query)
+			if err != nil {
+				return fmt.Errorf("sendProduction: %s", err.Error())
+			}
+			return nil
+		},
+		CommandAlerts: func(update tgbotapi.Update, user models.User) error {
+			err := bot.sendPriceAlerts(user, 0)
+			if err != nil {
+				return fmt.Errorf("sendPriceAlerts: %s", err.Error())
+			}
+			return nil
+		},
+		CommandPrice: func(update tgbotapi.Update, user models.User) error {
+			err := bot.sendPrices(user)
+			if err != nil {
+				return fmt.Errorf("sendPrices: %s", err.Error())
+			}
+			return nil
+		},
+	}
+}
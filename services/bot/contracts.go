@@ -0,0 +1,318 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"html"
+	"strconv"
+	"strings"
+)
+
+const (
+	contractAllCalls       = "-"
+	contractEventsFlag     = "events"
+	contractAnyArg         = "*"
+	maxContractFilterSize  = 1024 // size of the usa_functions and usa_args columns
+	maxContractEvents      = 10
+	maxContractEventLength = 256
+)
+
+var ErrNotContractSubscription = errors.New("not a contract subscription")
+
+type (
+	// contractFilter selects the calls of a contract subscription, an empty filter matches any call.
+	contractFilter struct {
+		functions []string
+		args      []argFilter
+		events    bool
+	}
+	argFilter struct {
+		position int // 1-based, 0 matches any argument
+		value    string
+	}
+)
+
+// parseContractFilter parses space separated tokens: function names, `N=value` (the N-th argument equals the value),
+// `*=value` (any argument equals the value) and `events`. `-` alone matches all calls.
+func parseContractFilter(text string) (filter contractFilter, ok bool) {
+	tokens := strings.Fields(text)
+	if len(tokens) == 0 {
+		return filter, false
+	}
+	if len(tokens) == 1 && tokens[0] == contractAllCalls {
+		return filter, true
+	}
+	for _, token := range tokens {
+		if strings.ToLower(token) == contractEventsFlag {
+			filter.events = true
+			continue
+		}
+		// the filters are stored comma separated
+		if strings.Contains(token, ",") {
+			return filter, false
+		}
+		if !strings.Contains(token, "=") {
+			filter.functions = append(filter.functions, token)
+			continue
+		}
+		arg, ok := parseArgFilter(token)
+		if !ok {
+			return filter, false
+		}
+		filter.args = append(filter.args, arg)
+	}
+	functions, args := filter.encode()
+	return filter, len(functions) <= maxContractFilterSize && len(args) <= maxContractFilterSize
+}
+
+func parseArgFilter(token string) (arg argFilter, ok bool) {
+	parts := strings.SplitN(token, "=", 2)
+	if parts[1] == "" {
+		return arg, false
+	}
+	arg.value = parts[1]
+	if parts[0] == contractAnyArg {
+		return arg, true
+	}
+	position, err := strconv.Atoi(parts[0])
+	if err != nil || position < 1 {
+		return arg, false
+	}
+	arg.position = position
+	return arg, true
+}
+
+// newContractFilter restores the filter stored with the subscription.
+func newContractFilter(functions string, args string, events bool) (filter contractFilter) {
+	filter.events = events
+	if functions != "" {
+		filter.functions = strings.Split(functions, ",")
+	}
+	if args == "" {
+		return filter
+	}
+	for _, token := range strings.Split(args, ",") {
+		arg, ok := parseArgFilter(token)
+		if ok {
+			filter.args = append(filter.args, arg)
+		}
+	}
+	return filter
+}
+
+func (filter contractFilter) encode() (functions string, args string) {
+	items := make([]string, len(filter.args))
+	for i, arg := range filter.args {
+		items[i] = arg.String()
+	}
+	return strings.Join(filter.functions, ","), strings.Join(items, ",")
+}
+
+func (arg argFilter) String() string {
+	if arg.position == 0 {
+		return contractAnyArg + "=" + arg.value
+	}
+	return fmt.Sprintf("%d=%s", arg.position, arg.value)
+}
+
+// match reports whether the call passes the filter, all the argument filters have to match.
+func (filter contractFilter) match(payload node.Payload) bool {
+	if len(filter.functions) != 0 && !containsString(filter.functions, payload.Function) {
+		return false
+	}
+	for _, arg := range filter.args {
+		if arg.position == 0 {
+			if !containsString(payload.Args, arg.value) {
+				return false
+			}
+			continue
+		}
+		if arg.position > len(payload.Args) || payload.Args[arg.position-1] != arg.value {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (bot *Bot) contractFilterText(filter contractFilter, lang string) string {
+	functions := bot.dictionary.Get("t.contract_any", lang)
+	if len(filter.functions) != 0 {
+		functions = strings.Join(filter.functions, ", ")
+	}
+	args := bot.dictionary.Get("t.contract_any", lang)
+	if len(filter.args) != 0 {
+		items := make([]string, len(filter.args))
+		for i, arg := range filter.args {
+			items[i] = arg.String()
+		}
+		args = strings.Join(items, ", ")
+	}
+	events := bot.dictionary.Get("t.contract_events_off", lang)
+	if filter.events {
+		events = bot.dictionary.Get("t.contract_events_on", lang)
+	}
+	return fmt.Sprintf(bot.dictionary.Get("t.contract_filter", lang), functions, args, events)
+}
+
+// setContractFilter replaces the filter of the contract subscription.
+func (bot *Bot) setContractFilter(user models.User, address string, filter contractFilter) error {
+	addressModel, userAddress, err := bot.findSubscription(user, address)
+	if err != nil {
+		return err
+	}
+	if userAddress.Type != models.AddressTypeContract {
+		return ErrNotContractSubscription
+	}
+	userAddress.Functions, userAddress.Args = filter.encode()
+	userAddress.Events = filter.events
+	err = bot.dao.UpdateUserAddress(userAddress)
+	if err != nil {
+		return fmt.Errorf("dao.UpdateUserAddress: %s", err.Error())
+	}
+	bot.addContractAddress(user, addressModel, filter)
+	return nil
+}
+
+func (bot *Bot) addContractAddress(user models.User, address models.Address, filter contractFilter) {
+	bot.mu.Lock()
+	_, ok := bot.contracts[address.Address]
+	if !ok {
+		bot.contracts[address.Address] = make(map[uint64]contractFilter)
+	}
+	bot.contracts[address.Address][user.ID] = filter
+	bot.mu.Unlock()
+}
+
+// watchesCalls reports whether the tx is a call the user gets from contractNotify instead of txNotify,
+// transfers to a watched contract are still reported by txNotify. mu must be held.
+func (bot *Bot) watchesCalls(userID uint64, address string, tx node.Transaction) bool {
+	if tx.Type != node.TxTypeCall || tx.To != address {
+		return false
+	}
+	_, ok := bot.contracts[address][userID]
+	return ok
+}
+
+// contractNotify alerts the contract subscribers about the calls passing their filters,
+// the events are read from the node once per tx and only when a matched filter asks for them.
+func (bot *Bot) contractNotify(tx node.Transaction) {
+	if tx.Type != node.TxTypeCall {
+		return
+	}
+	filters := make(map[uint64]contractFilter)
+	users := make(map[uint64]models.User)
+	bot.mu.RLock()
+	for userID, filter := range bot.contracts[tx.To] {
+		user, ok := bot.users[userID]
+		if !ok || user.Mute {
+			continue
+		}
+		filters[userID] = filter
+		users[userID] = user
+	}
+	bot.mu.RUnlock()
+	if len(users) == 0 {
+		return
+	}
+	payload, err := node.DecodePayload(tx)
+	if err != nil {
+		log.Warn("Bot: contractNotify: node.DecodePayload(%s): %s", tx.Hash, err.Error())
+		return
+	}
+	var (
+		events       []node.Event
+		eventsLoaded bool
+		eventsErr    error
+	)
+	for userID, user := range users {
+		filter := filters[userID]
+		if !filter.match(payload) {
+			continue
+		}
+		if filter.events && !eventsLoaded {
+			events, eventsErr = bot.node.GetEventsByHash(tx.Hash)
+			if eventsErr != nil {
+				log.Error("Bot: contractNotify: node.GetEventsByHash(%s): %s", tx.Hash, eventsErr.Error())
+			}
+			eventsLoaded = true
+		}
+		text := bot.contractCallText(user, tx, payload)
+		if filter.events {
+			text += bot.contractEventsText(events, eventsErr, user.Lang)
+		}
+		url := fmt.Sprintf("https://explorer.nebulas.io/#/tx/%s", tx.Hash)
+		msg := tgbotapi.NewMessage(user.TgID, text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonURL(bot.dictionary.Get("b.link", user.Lang), url),
+			),
+		)
+		err = bot.sendMsg(msg)
+		if err != nil {
+			log.Error("Bot: contractNotify: sendMsg: %s", err.Error())
+		}
+	}
+}
+
+func (bot *Bot) contractCallText(user models.User, tx node.Transaction, payload node.Payload) string {
+	aliases := bot.userAliases(user)
+	contract := bot.addressLabel(tx.To, aliases, user.Lang)
+	if contract == "" {
+		contract = shortAddress(tx.To)
+	}
+	status := "success"
+	if tx.Status != 1 {
+		status = "failed"
+	}
+	return fmt.Sprintf(
+		bot.dictionary.Get("t.contract_call", user.Lang),
+		html.EscapeString(contract),
+		html.EscapeString(payload.Function),
+		callArgsText(payload.Args),
+		tx.From,
+		tx.Value.Div(node.PrecisionDivNAS).Truncate(4).String(),
+		status,
+		tx.BlockHeight,
+		tx.Hash,
+	)
+}
+
+// contractEventsText lists the events emitted by the call, the closing transaction result event is skipped.
+func (bot *Bot) contractEventsText(events []node.Event, err error, lang string) string {
+	if err != nil {
+		return bot.dictionary.Get("t.contract_events_unavailable", lang)
+	}
+	var lines []string
+	for _, event := range events {
+		if event.Topic == node.TopicTransactionResult {
+			continue
+		}
+		if len(lines) == maxContractEvents {
+			lines = append(lines, "…")
+			break
+		}
+		data := event.Data
+		if runes := []rune(data); len(runes) > maxContractEventLength {
+			data = string(runes[:maxContractEventLength]) + "…"
+		}
+		lines = append(lines, fmt.Sprintf("<b>%s</b>: <code>%s</code>", html.EscapeString(event.Topic), html.EscapeString(data)))
+	}
+	if len(lines) == 0 {
+		return bot.dictionary.Get("t.contract_no_events", lang)
+	}
+	return fmt.Sprintf(bot.dictionary.Get("t.contract_events", lang), strings.Join(lines, "\n"))
+}
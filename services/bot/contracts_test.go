@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseContractFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want contractFilter
+		ok   bool
+	}{
+		{name: "all calls", text: "-", ok: true},
+		{name: "empty", text: "  ", ok: false},
+		{name: "functions", text: "vote cancelVote", want: contractFilter{functions: []string{"vote", "cancelVote"}}, ok: true},
+		{
+			name: "args and events",
+			text: "transfer 1=n1abc *=42 EVENTS",
+			want: contractFilter{
+				functions: []string{"transfer"},
+				args:      []argFilter{{position: 1, value: "n1abc"}, {position: 0, value: "42"}},
+				events:    true,
+			},
+			ok: true,
+		},
+		{name: "comma", text: "vote,transfer", ok: false},
+		{name: "empty value", text: "1=", ok: false},
+		{name: "zero position", text: "0=x", ok: false},
+		{name: "bad position", text: "a=x", ok: false},
+		{name: "too long", text: strings.Repeat("f", maxContractFilterSize+1), ok: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseContractFilter(test.text)
+			if ok != test.ok {
+				t.Fatalf("parseContractFilter(%q) ok = %t, want %t", test.text, ok, test.ok)
+			}
+			if ok && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseContractFilter(%q) = %+v, want %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestContractFilterEncode(t *testing.T) {
+	filter, ok := parseContractFilter("vote transfer 2=10 *=n1abc events")
+	if !ok {
+		t.Fatal("parseContractFilter failed")
+	}
+	functions, args := filter.encode()
+	restored := newContractFilter(functions, args, filter.events)
+	if !reflect.DeepEqual(restored, filter) {
+		t.Errorf("newContractFilter(%q, %q) = %+v, want %+v", functions, args, restored, filter)
+	}
+}
+
+func TestContractFilterMatch(t *testing.T) {
+	payload := node.Payload{Function: "transfer", Args: []string{"n1abc", "10"}}
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "all calls", text: "-", want: true},
+		{name: "function", text: "vote transfer", want: true},
+		{name: "other function", text: "vote", want: false},
+		{name: "arg", text: "2=10", want: true},
+		{name: "arg mismatch", text: "1=10", want: false},
+		{name: "arg out of range", text: "3=10", want: false},
+		{name: "any arg", text: "*=n1abc", want: true},
+		{name: "any arg mismatch", text: "*=n1xyz", want: false},
+		{name: "all args have to match", text: "transfer 1=n1abc 2=11", want: false},
+		{name: "events only", text: "events", want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, ok := parseContractFilter(test.text)
+			if !ok {
+				t.Fatalf("parseContractFilter(%q) failed", test.text)
+			}
+			got := filter.match(payload)
+			if got != test.want {
+				t.Errorf("match(%q) = %t, want %t", test.text, got, test.want)
+			}
+		})
+	}
+}
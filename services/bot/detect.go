@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"strings"
+)
+
+const (
+	roleRegistrant       = "registrant"
+	roleConsensusManager = "consensus_manager"
+	roleGovManager       = "gov_manager"
+	roleStakingAccount   = "staking_account"
+)
+
+type addressInfo struct {
+	Type     string
+	Contract bool
+	NodeID   string
+	NodeName string
+	Roles    []string
+}
+
+// detectAddress classifies the address: validator accounts are matched against the known nodes,
+// contracts are recognized by the account state type.
+func (bot *Bot) detectAddress(address string) (info addressInfo, err error) {
+	info.Type = models.AddressTypeAccount
+	bot.mu.RLock()
+	for _, n := range bot.nodes {
+		roles := nodeRoles(n, address)
+		if len(roles) != 0 {
+			info.Type = models.AddressTypeValidator
+			info.NodeID = n.ID
+			info.NodeName = n.Info.Name
+			info.Roles = roles
+			break
+		}
+	}
+	bot.mu.RUnlock()
+	addr, err := node.ParseAddress(address)
+	if err != nil {
+		return info, fmt.Errorf("node.ParseAddress: %s", err.Error())
+	}
+	info.Contract = addr.IsContract()
+	state, err := bot.node.GetAccountState(address)
+	if err != nil {
+		return info, fmt.Errorf("node.GetAccountState: %s", err.Error())
+	}
+	if state.Result.Type != 0 {
+		info.Contract = byte(state.Result.Type) == node.AddressTypeContract
+	}
+	if info.Contract && info.Type == models.AddressTypeAccount {
+		info.Type = models.AddressTypeContract
+	}
+	return info, nil
+}
+
+func nodeRoles(n node.ValidatorNode, address string) (roles []string) {
+	if n.Accounts.Registrant == address {
+		roles = append(roles, roleRegistrant)
+	}
+	if n.Accounts.ConsensusManager == address {
+		roles = append(roles, roleConsensusManager)
+	}
+	if n.Accounts.GovManager == address {
+		roles = append(roles, roleGovManager)
+	}
+	if n.Accounts.StakingAccount == address {
+		roles = append(roles, roleStakingAccount)
+	}
+	return roles
+}
+
+func (bot *Bot) addressInfoText(info addressInfo, lang string) string {
+	if info.Type == models.AddressTypeValidator {
+		roles := make([]string, 0, len(info.Roles))
+		for _, role := range info.Roles {
+			roles = append(roles, bot.dictionary.Get("t.role_"+role, lang))
+		}
+		return fmt.Sprintf(
+			bot.dictionary.Get("t.detected_validator", lang),
+			info.NodeID,
+			info.NodeName,
+			strings.Join(roles, ", "),
+		)
+	}
+	if info.Contract {
+		return bot.dictionary.Get("t.detected_contract", lang)
+	}
+	return bot.dictionary.Get("t.detected_account", lang)
+}
@@ -0,0 +1,276 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"time"
+)
+
+// blockTime is approximate, it is used only to estimate dates of future blocks.
+const blockTime = time.Second * 15
+
+// governanceState is the current governance period, it is accessed from the Parsing goroutine only.
+type governanceState struct {
+	period       models.GovernancePeriod
+	participants map[string]models.GovernanceParticipant // [nodeID]
+}
+
+func (bot *Bot) governancePeriodLength() uint64 {
+	return bot.cfg.Governance.CycleBlocks * bot.cfg.Governance.PeriodCycles
+}
+
+// participationTracked is false without the governance contract: the gov managers also send ordinary votes
+// to the staking contract, so the transactions can't be told apart.
+func (bot *Bot) participationTracked() bool {
+	return bot.cfg.Governance.Contract != ""
+}
+
+// governancePeriodAt returns the governance period containing the block, ok is false before the first period.
+func (bot *Bot) governancePeriodAt(h uint64) (period models.GovernancePeriod, ok bool) {
+	start := bot.cfg.Governance.StartBlock
+	length := bot.governancePeriodLength()
+	if h < start || length == 0 {
+		return period, false
+	}
+	period.Number = (h-start)/length + 1
+	period.StartBlock = start + (period.Number-1)*length
+	period.EndBlock = period.StartBlock + length - 1
+	return period, true
+}
+
+// trackGovernance switches governance periods and sends the announcements and reminders due at the block.
+func (bot *Bot) trackGovernance(h uint64) error {
+	period, ok := bot.governancePeriodAt(h)
+	if !ok {
+		return nil
+	}
+	if bot.governance.period.Number != period.Number {
+		// the results are announced once, also when the bot was down at the start block
+		if period.Number > 1 {
+			err := bot.finishGovernancePeriod(period.Number - 1)
+			if err != nil {
+				return fmt.Errorf("finishGovernancePeriod: %s", err.Error())
+			}
+		}
+		err := bot.loadGovernancePeriod(period)
+		if err != nil {
+			return fmt.Errorf("loadGovernancePeriod: %s", err.Error())
+		}
+		if h == period.StartBlock {
+			bot.announceGovernancePeriod()
+		}
+	}
+	err := bot.remindGovernance(h)
+	if err != nil {
+		return fmt.Errorf("remindGovernance: %s", err.Error())
+	}
+	return nil
+}
+
+// remindGovernance sends the latest reminder due at the block. The reminded block is stored before sending,
+// so a block parsed again after an error does not repeat it, and a reminder missed while the bot was down is caught up.
+// The reminders are about the participation, so they are not sent while it is not tracked.
+func (bot *Bot) remindGovernance(h uint64) error {
+	if !bot.participationTracked() {
+		return nil
+	}
+	period := bot.governance.period
+	var due uint64
+	for _, blocks := range bot.cfg.Governance.Reminders {
+		if blocks == 0 || blocks >= bot.governancePeriodLength() {
+			continue
+		}
+		at := period.EndBlock + 1 - blocks
+		if at <= h && at > period.RemindedBlock && at > due {
+			due = at
+		}
+	}
+	if due == 0 {
+		return nil
+	}
+	period.RemindedBlock = due
+	err := bot.dao.UpdateGovernancePeriod(period)
+	if err != nil {
+		return fmt.Errorf("dao.UpdateGovernancePeriod: %s", err.Error())
+	}
+	bot.governance.period = period
+	bot.remindGovManagers(period.EndBlock + 1 - h)
+	return nil
+}
+
+// loadGovernancePeriod restores the period participants, a new period takes consensus and candidate nodes.
+func (bot *Bot) loadGovernancePeriod(period models.GovernancePeriod) error {
+	participants := make(map[string]models.GovernanceParticipant)
+	stored, err := bot.dao.GetGovernancePeriod(period.Number)
+	if err != nil {
+		if err.Error() != derrors.ErrNotFound {
+			return fmt.Errorf("dao.GetGovernancePeriod: %s", err.Error())
+		}
+		err = bot.dao.CreateGovernancePeriod(period)
+		if err != nil {
+			return fmt.Errorf("dao.CreateGovernancePeriod: %s", err.Error())
+		}
+		var items []models.GovernanceParticipant
+		bot.mu.RLock()
+		for _, n := range bot.nodes {
+			if n.Type == consensusNode || n.Type == candidateNode {
+				items = append(items, models.GovernanceParticipant{
+					PeriodNumber: period.Number,
+					NodeID:       n.ID,
+					GovManager:   n.Accounts.GovManager,
+				})
+			}
+		}
+		bot.mu.RUnlock()
+		err = bot.dao.CreateGovernanceParticipants(items)
+		if err != nil {
+			return fmt.Errorf("dao.CreateGovernanceParticipants: %s", err.Error())
+		}
+		for _, item := range items {
+			participants[item.NodeID] = item
+		}
+	} else {
+		period = stored
+		items, err := bot.dao.GetGovernanceParticipants(filters.GovernanceParticipants{PeriodNumbers: []uint64{period.Number}})
+		if err != nil {
+			return fmt.Errorf("dao.GetGovernanceParticipants: %s", err.Error())
+		}
+		for _, item := range items {
+			participants[item.NodeID] = item
+		}
+	}
+	bot.governance = governanceState{
+		period:       period,
+		participants: participants,
+	}
+	return nil
+}
+
+// trackGovernanceTx marks the participation of the node whose gov manager called the governance contract.
+func (bot *Bot) trackGovernanceTx(h uint64, tx node.Transaction) {
+	if !bot.participationTracked() || tx.Status != 1 || tx.To != bot.cfg.Governance.Contract {
+		return
+	}
+	for id, p := range bot.governance.participants {
+		if p.Participated || p.GovManager != tx.From {
+			continue
+		}
+		p.Participated = true
+		p.Block = h
+		bot.governance.participants[id] = p
+		err := bot.dao.UpdateGovernanceParticipant(p)
+		if err != nil {
+			log.Error("Bot: trackGovernanceTx: dao.UpdateGovernanceParticipant: %s", err.Error())
+		}
+	}
+}
+
+func (bot *Bot) announceGovernancePeriod() {
+	period := bot.governance.period
+	endDate := time.Now().Add(blockTime * time.Duration(period.EndBlock-period.StartBlock)).Format("2006-01-02 15:04")
+	bot.notifyParticipants(bot.governance.participants, func(n node.ValidatorNode, p models.GovernanceParticipant, lang string) string {
+		return fmt.Sprintf(
+			bot.dictionary.Get("t.governance_started", lang),
+			period.Number,
+			nodeTitle(n),
+			period.StartBlock,
+			period.EndBlock,
+			endDate,
+			p.GovManager,
+		)
+	})
+}
+
+func (bot *Bot) remindGovManagers(blocksLeft uint64) {
+	period := bot.governance.period
+	pending := make(map[string]models.GovernanceParticipant)
+	for id, p := range bot.governance.participants {
+		if !p.Participated {
+			pending[id] = p
+		}
+	}
+	hoursLeft := (blockTime * time.Duration(blocksLeft)).Hours()
+	bot.notifyParticipants(pending, func(n node.ValidatorNode, p models.GovernanceParticipant, lang string) string {
+		return fmt.Sprintf(
+			bot.dictionary.Get("t.governance_reminder", lang),
+			p.GovManager,
+			nodeTitle(n),
+			period.Number,
+			blocksLeft,
+			hoursLeft,
+		)
+	})
+}
+
+// finishGovernancePeriod announces the results of the period unless they were announced already,
+// nothing is sent for a period the bot has not tracked.
+func (bot *Bot) finishGovernancePeriod(number uint64) error {
+	period, err := bot.dao.GetGovernancePeriod(number)
+	if err != nil {
+		if err.Error() == derrors.ErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("dao.GetGovernancePeriod: %s", err.Error())
+	}
+	if period.Finished {
+		return nil
+	}
+	period.Finished = true
+	err = bot.dao.UpdateGovernancePeriod(period)
+	if err != nil {
+		return fmt.Errorf("dao.UpdateGovernancePeriod: %s", err.Error())
+	}
+	participants := bot.governance.participants
+	if bot.governance.period.Number != number {
+		items, err := bot.dao.GetGovernanceParticipants(filters.GovernanceParticipants{PeriodNumbers: []uint64{number}})
+		if err != nil {
+			return fmt.Errorf("dao.GetGovernanceParticipants: %s", err.Error())
+		}
+		participants = make(map[string]models.GovernanceParticipant)
+		for _, item := range items {
+			participants[item.NodeID] = item
+		}
+	}
+	bot.notifyParticipants(participants, func(n node.ValidatorNode, p models.GovernanceParticipant, lang string) string {
+		result := bot.dictionary.Get("t.governance_missed", lang)
+		if !bot.participationTracked() {
+			result = bot.dictionary.Get("t.governance_not_tracked", lang)
+		} else if p.Participated {
+			result = fmt.Sprintf(bot.dictionary.Get("t.governance_participated", lang), p.Block)
+		}
+		return fmt.Sprintf(bot.dictionary.Get("t.governance_finished", lang), number, nodeTitle(n), result)
+	})
+	return nil
+}
+
+func (bot *Bot) notifyParticipants(
+	participants map[string]models.GovernanceParticipant,
+	text func(n node.ValidatorNode, p models.GovernanceParticipant, lang string) string,
+) {
+	var messages []tgbotapi.MessageConfig
+	bot.mu.RLock()
+	for id, p := range participants {
+		n, ok := bot.nodes[id]
+		if !ok {
+			n.ID = id
+		}
+		for _, user := range bot.nodeUsers(n) {
+			if user.Mute {
+				continue
+			}
+			messages = append(messages, tgbotapi.NewMessage(user.TgID, text(n, p, user.Lang)))
+		}
+	}
+	bot.mu.RUnlock()
+	for _, msg := range messages {
+		err := bot.sendMsg(msg)
+		if err != nil {
+			log.Error("Bot: notifyParticipants: api.Send: %s", err.Error())
+		}
+	}
+}
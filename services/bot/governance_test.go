@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/config"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"testing"
+)
+
+func TestTrackGovernanceTx(t *testing.T) {
+	const (
+		govManager = "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"
+		contract   = "n1etmdwczuAUCnMMvpGasfi8kwUbb2ddvRJ"
+	)
+	tests := []struct {
+		name     string
+		contract string
+		tx       node.Transaction
+		want     bool
+	}{
+		{
+			name: "staking vote without the governance contract",
+			tx:   node.Transaction{From: govManager, To: StakingContract, Status: 1, Type: node.TxTypeCall},
+		},
+		{
+			name:     "staking vote with the governance contract",
+			contract: contract,
+			tx:       node.Transaction{From: govManager, To: StakingContract, Status: 1, Type: node.TxTypeCall},
+		},
+		{
+			name:     "governance call",
+			contract: contract,
+			tx:       node.Transaction{From: govManager, To: contract, Status: 1, Type: node.TxTypeCall},
+			want:     true,
+		},
+		{
+			name:     "failed governance call",
+			contract: contract,
+			tx:       node.Transaction{From: govManager, To: contract, Status: 0, Type: node.TxTypeCall},
+		},
+		{
+			name:     "call of another account",
+			contract: contract,
+			tx:       node.Transaction{From: StakingContract, To: contract, Status: 1, Type: node.TxTypeCall},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Governance.Contract = test.contract
+			bot, d := newTestBot(cfg)
+			bot.governance.participants = map[string]models.GovernanceParticipant{
+				"node": {PeriodNumber: 1, NodeID: "node", GovManager: govManager},
+			}
+			bot.trackGovernanceTx(100, test.tx)
+			p := bot.governance.participants["node"]
+			if p.Participated != test.want {
+				t.Fatalf("Participated = %t, want %t", p.Participated, test.want)
+			}
+			if test.want && (p.Block != 100 || len(d.participants) != 1) {
+				t.Errorf("participant = %+v, stored %d times", p, len(d.participants))
+			}
+			if !test.want && len(d.participants) != 0 {
+				t.Errorf("participant stored %d times", len(d.participants))
+			}
+		})
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"github.com/everstake/nebulas-tg-bot/dao/filters"
 	"github.com/everstake/nebulas-tg-bot/log"
 	"github.com/everstake/nebulas-tg-bot/models"
-	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/everstake/nebulas-tg-bot/services/market"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/shopspring/decimal"
+	"sync"
+	"time"
 )
 
 func (bot *Bot) GetCachedItem(userID uint64, key string) (item interface{}, found bool) {
@@ -62,13 +64,15 @@ func (bot *Bot) oops(user models.User) error {
 }
 
 func (bot *Bot) showSubscriptions(user models.User) (err error) {
-	nasPrice := bot.market.GetNASPrice()
-	naxPrice := bot.market.GetNAXPrice()
 	states, err := bot.getSubscriptions(user)
 	if err != nil {
 		return fmt.Errorf("getSubscriptions: %s", err.Error())
 	}
-	if len(states) == 0 {
+	nodesCount, err := bot.showNodeSubscriptions(user)
+	if err != nil {
+		return fmt.Errorf("showNodeSubscriptions: %s", err.Error())
+	}
+	if len(states) == 0 && nodesCount == 0 {
 		msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.not_have_addresses", user.Lang))
 		_, err := bot.api.Send(msg)
 		if err != nil {
@@ -76,35 +80,59 @@ func (bot *Bot) showSubscriptions(user models.User) (err error) {
 		}
 		return nil
 	}
-	for _, state := range states {
+	err = bot.sendPortfolio(user, states)
+	if err != nil {
+		return fmt.Errorf("sendPortfolio: %s", err.Error())
+	}
+	err = bot.sendSubscriptionsPage(user, states, 0)
+	if err != nil {
+		return fmt.Errorf("sendSubscriptionsPage: %s", err.Error())
+	}
+	return nil
+}
+
+// sendSubscriptionsPage sends a card per address of the page and the page switcher when there are several pages.
+func (bot *Bot) sendSubscriptionsPage(user models.User, states []models.AddressState, page int) error {
+	from := page * subscriptionsPageSize
+	if page < 0 || from >= len(states) {
+		return nil
+	}
+	to := minInt(from+subscriptionsPageSize, len(states))
+	nasPrice := bot.market.GetNASPrice()
+	naxPrice := bot.market.GetNAXPrice()
+	rewards, err := bot.getRewardsSummary(states[from:to])
+	if err != nil {
+		return fmt.Errorf("getRewardsSummary: %s", err.Error())
+	}
+	for _, state := range states[from:to] {
 		var text string
-		switch state.Type {
-		case models.AddressTypeAccount:
+		switch {
+		case state.Unavailable:
+			text = fmt.Sprintf(bot.dictionary.Get("t.address_unavailable", user.Lang), state.Alias, state.Address)
+		case state.Type == models.AddressTypeContract:
 			text = fmt.Sprintf(
-				bot.dictionary.Get("t.address_subscription", user.Lang),
+				bot.dictionary.Get("t.contract_subscription", user.Lang),
 				state.Alias,
 				state.Address,
-				state.NAS.Truncate(4).String(),
-				state.NAS.Mul(nasPrice).Truncate(4).String(),
-				state.NAX.Truncate(4).String(),
-				state.NAX.Mul(naxPrice).Truncate(6).String(),
-				state.Type,
-				state.VotedAmount.Truncate(4).String(),
+				bot.contractFilterText(newContractFilter(state.Functions, state.Args, state.Events), user.Lang),
 			)
-		case models.AddressTypeValidator:
+		default:
+			// validator subscriptions not converted yet (see convertValidatorSubscriptions) are shown as accounts
 			text = fmt.Sprintf(
-				bot.dictionary.Get("t.validator_subscription", user.Lang),
+				bot.dictionary.Get("t.address_subscription", user.Lang),
 				state.Alias,
 				state.Address,
 				state.NAS.Truncate(4).String(),
-				state.NAS.Mul(nasPrice).Truncate(4).String(),
+				bot.fiatValue(state.NAS.Mul(nasPrice), user.Currency, 4),
 				state.NAX.Truncate(4).String(),
-				state.NAX.Mul(naxPrice).Truncate(6).String(),
+				bot.fiatValue(state.NAX.Mul(naxPrice), user.Currency, 6),
 				state.Type,
-				state.TotalVotes,
+				state.VotedAmount.Truncate(4).String(),
 			)
-		default:
-			continue
+		}
+		if state.Type != models.AddressTypeContract {
+			text += bot.rewardsText(rewards, state, user.Lang)
+			text += bot.stalePricesText(user.Lang)
 		}
 
 		url := fmt.Sprintf("https://explorer.nebulas.io/#/address/%s", state.Address)
@@ -114,7 +142,20 @@ func (bot *Bot) showSubscriptions(user models.User) (err error) {
 				tgbotapi.NewInlineKeyboardButtonURL(bot.dictionary.Get("b.link", user.Lang), url),
 				tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.delete", user.Lang), action),
 			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.webhook", user.Lang), fmt.Sprintf("webhook_%s", state.Address)),
+			),
 		)
+		if state.Type == models.AddressTypeContract {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.contract_filter", user.Lang), fmt.Sprintf("cfilter_%s", state.Address)),
+			))
+		}
+		if state.NodeID != "" {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.voters", user.Lang), fmt.Sprintf("voters_%s", state.NodeID)),
+			))
+		}
 		msg := tgbotapi.NewMessage(user.TgID, text)
 		msg.ReplyMarkup = keyboard
 		_, err := bot.api.Send(msg)
@@ -122,13 +163,54 @@ func (bot *Bot) showSubscriptions(user models.User) (err error) {
 			return fmt.Errorf("api.Send: %s", err.Error())
 		}
 	}
+	pages := (len(states) + subscriptionsPageSize - 1) / subscriptionsPageSize
+	if pages > 1 {
+		err = bot.sendSubscriptionsPager(user, page, pages, from+1, to, len(states))
+		if err != nil {
+			return fmt.Errorf("sendSubscriptionsPager: %s", err.Error())
+		}
+	}
 	return nil
 }
 
+// currencyPlaces are the min decimal places of the currencies with a high unit price.
+var currencyPlaces = map[string]int32{"BTC": 8, "ETH": 6}
+
+// fiatValue formats the USDT value in the currency, USD is used while the currency rate is unknown.
+func (bot *Bot) fiatValue(value decimal.Decimal, currency string, places int32) string {
+	converted, ok := bot.market.Convert(value, currency)
+	if !ok {
+		converted, currency = value, market.CurrencyUSD
+	}
+	return formatCurrency(converted, currency, places)
+}
+
+func formatCurrency(value decimal.Decimal, currency string, places int32) string {
+	if p, ok := currencyPlaces[currency]; ok && p > places {
+		places = p
+	}
+	return fmt.Sprintf("%s %s", value.Truncate(places).String(), currency)
+}
+
+// stalePricesText labels the prices that were never fetched or not refreshed for market.StaleAfter.
+func (bot *Bot) stalePricesText(lang string) (text string) {
+	for _, asset := range []string{market.AssetNAS, market.AssetNAX} {
+		updated := bot.market.LastUpdated(asset)
+		if updated.IsZero() {
+			text += "\n" + fmt.Sprintf(bot.dictionary.Get("t.price_unavailable", lang), asset)
+			continue
+		}
+		age := time.Since(updated)
+		if age > market.StaleAfter {
+			text += "\n" + fmt.Sprintf(bot.dictionary.Get("t.price_stale", lang), asset, int(age.Minutes()))
+		}
+	}
+	return text
+}
+
 func (bot *Bot) getSubscriptions(user models.User) (states []models.AddressState, err error) {
 	addresses, err := bot.dao.GetUsersAddressReports(filters.UsersAddresses{
 		UserID: []uint64{user.ID},
-		Limit:  20,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("dao.GetUsersAddressReports: %s", err.Error())
@@ -136,87 +218,45 @@ func (bot *Bot) getSubscriptions(user models.User) (states []models.AddressState
 	if len(addresses) == 0 {
 		return nil, nil
 	}
-	errChan := make(chan error)
-	stateCh := make(chan models.AddressState)
-	defer close(errChan)
-	defer close(stateCh)
-
+	states = make([]models.AddressState, len(addresses))
+	sem := make(chan struct{}, subscriptionsWorkers)
+	var wg sync.WaitGroup
 	for i := range addresses {
+		wg.Add(1)
+		sem <- struct{}{}
 		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
 			address := addresses[i]
-			as, err := bot.node.GetAccountState(address.Address)
-			if err != nil {
-				errChan <- fmt.Errorf("node.GetAccountState: %s", err.Error())
+			bot.mu.RLock()
+			nodeID := bot.nodeAccounts[address.Address]
+			bot.mu.RUnlock()
+			states[i] = models.AddressState{
+				Address: address.Address,
+				Alias:   address.Alias,
+				Type:    address.Type,
+				NodeID:  nodeID,
+			}
+			if address.Type == models.AddressTypeContract {
+				states[i].Functions = address.Functions
+				states[i].Args = address.Args
+				states[i].Events = address.Events
 				return
 			}
-			naxBalance, err := bot.node.GetNAXBalance(address.Address)
+			balances, err := bot.getAddressBalances(address.Address)
 			if err != nil {
-				errChan <- fmt.Errorf("node.GetNAXBalance: %s", err.Error())
+				log.Error("Bot: getSubscriptions: getAddressBalances(%s): %s", address.Address, err.Error())
+				states[i].Unavailable = true
 				return
 			}
-			totalVotes := decimal.Zero
-			votedAmount := decimal.Zero
-			if address.Type == models.AddressTypeValidator {
-				var nodeID string
-				bot.mu.RLock()
-				for _, n := range bot.nodes {
-					if n.Accounts.StakingAccount == address.Address ||
-						n.Accounts.Registrant == address.Address ||
-						n.Accounts.GovManager == address.Address ||
-						n.Accounts.ConsensusManager == address.Address {
-
-						nodeID = n.ID
-						break
-					}
-				}
-				bot.mu.RUnlock()
-				if nodeID != "" {
-					list, err := bot.node.GetNodeVotesList(nodeID)
-					if err != nil {
-						log.Error("getSubscriptions: node.GetNodeVotesList: %s", err.Error())
-					} else {
-						for _, vote := range list {
-							totalVotes = totalVotes.Add(vote.Value)
-						}
-						totalVotes = totalVotes.Div(node.PrecisionDivNAX)
-					}
-				}
-			} else {
-				votedAmount, err = bot.node.GetVotedNAX(address.Address)
-				if err != nil {
-					log.Error("getSubscriptions: node.GetVotedNAX: %s", err.Error())
-				} else {
-					votedAmount = votedAmount.Div(node.PrecisionDivNAX)
-				}
-			}
-			stateCh <- models.AddressState{
-				Address:     address.Address,
-				NAS:         as.Result.Balance.Div(node.PrecisionDivNAS),
-				NAX:         naxBalance.Div(node.PrecisionDivNAX),
-				Alias:       address.Alias,
-				Type:        address.Type,
-				TotalVotes:  totalVotes,
-				VotedAmount: votedAmount,
-			}
+			states[i].NAS = balances.nas
+			states[i].NAX = balances.nax
+			states[i].VotedAmount = balances.votedAmount
 		}(i)
 	}
-
-	for {
-		exit := false
-		select {
-		case err = <-errChan:
-			return nil, err
-		case s := <-stateCh:
-			states = append(states, s)
-			if len(states) == len(addresses) {
-				exit = true
-			}
-		}
-		if exit {
-			break
-		}
-	}
-
+	wg.Wait()
 	return states, nil
 }
 
@@ -238,6 +278,15 @@ func getUniqStrings(items []string) []string {
 
 }
 
+func (bot *Bot) sendText(user models.User, text string) error {
+	msg := tgbotapi.NewMessage(user.TgID, text)
+	_, err := bot.api.Send(msg)
+	if err != nil {
+		return fmt.Errorf("api.Send: %s", err.Error())
+	}
+	return nil
+}
+
 func (bot *Bot) sendMsg(msg tgbotapi.Chattable) error {
 	_, err := bot.api.Send(msg)
 	if err != nil {
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	maxImportFileSize = 1 << 20
+	maxImportRecords  = 1000
+	maxImportErrors   = 20
+	// importTimeout limits the download of the document, the import blocks the updates loop
+	importTimeout = time.Second * 30
+
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+)
+
+var importClient = &http.Client{Timeout: importTimeout}
+
+type subscriptionRecord struct {
+	Address string `json:"address"`
+	Alias   string `json:"alias"`
+	Type    string `json:"type"`
+}
+
+// importSubscriptions subscribes the user to every address from the uploaded CSV or JSON document.
+func (bot *Bot) importSubscriptions(update tgbotapi.Update, user models.User) error {
+	doc := update.Message.Document
+	if doc.FileSize > maxImportFileSize {
+		return bot.sendText(user, bot.dictionary.Get("t.import_too_large", user.Lang))
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(doc.FileName)), ".")
+	if format != exportFormatCSV && format != exportFormatJSON {
+		return bot.sendText(user, bot.dictionary.Get("t.import_invalid_file", user.Lang))
+	}
+	url, err := bot.api.GetFileDirectURL(doc.FileID)
+	if err != nil {
+		return fmt.Errorf("api.GetFileDirectURL: %s", err.Error())
+	}
+	resp, err := importClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("http.Get: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxImportFileSize))
+	if err != nil {
+		return fmt.Errorf("ioutil.ReadAll: %s", err.Error())
+	}
+	var records []subscriptionRecord
+	if format == exportFormatJSON {
+		err = json.Unmarshal(data, &records)
+	} else {
+		records, err = parseCSVRecords(data)
+	}
+	if err != nil || len(records) == 0 {
+		return bot.sendText(user, bot.dictionary.Get("t.import_invalid_file", user.Lang))
+	}
+	total := len(records)
+	if total > maxImportRecords {
+		records = records[:maxImportRecords]
+	}
+
+	var added, duplicates int
+	var errs []string
+	for i, r := range records {
+		if r.Type == "" {
+			r.Type = models.AddressTypeAccount
+		}
+		err = bot.Subscribe(user, strings.TrimSpace(r.Address), strings.TrimSpace(r.Alias), strings.ToLower(strings.TrimSpace(r.Type)))
+		switch {
+		case err == nil:
+			added++
+		case err.Error() == derrors.ErrDuplicate:
+			duplicates++
+		case node.IsAddressError(err), err == ErrInvalidAddressType, err == ErrNotValidatorAccount:
+			errs = append(errs, fmt.Sprintf("#%d %s: %s", i+1, r.Address, err.Error()))
+		default:
+			log.Error("Bot: importSubscriptions: Subscribe(%s): %s", r.Address, err.Error())
+			errs = append(errs, fmt.Sprintf("#%d %s: %s", i+1, r.Address, bot.dictionary.Get("t.import_record_failed", user.Lang)))
+		}
+	}
+	text := fmt.Sprintf(bot.dictionary.Get("t.import_summary", user.Lang), total, added, duplicates, len(errs))
+	if total > maxImportRecords {
+		text += fmt.Sprintf(bot.dictionary.Get("t.import_truncated", user.Lang), maxImportRecords, total-maxImportRecords)
+	}
+	if len(errs) > maxImportErrors {
+		errs = append(errs[:maxImportErrors], "...")
+	}
+	if len(errs) != 0 {
+		text = fmt.Sprintf("%s\n\n%s", text, strings.Join(errs, "\n"))
+	}
+	return bot.sendText(user, text)
+}
+
+// parseCSVRecords reads `address,alias,type` rows, the header row is optional.
+func parseCSVRecords(data []byte) (records []subscriptionRecord, err error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		if len(row) == 0 || (len(row) == 1 && row[0] == "") {
+			continue
+		}
+		if i == 0 && strings.EqualFold(strings.TrimSpace(row[0]), "address") {
+			continue
+		}
+		r := subscriptionRecord{Address: row[0]}
+		if len(row) > 1 {
+			r.Alias = row[1]
+		}
+		if len(row) > 2 {
+			r.Type = row[2]
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (bot *Bot) exportSubscriptions(user models.User, format string) error {
+	items, err := bot.dao.GetUsersAddressReports(filters.UsersAddresses{UserID: []uint64{user.ID}})
+	if err != nil {
+		return fmt.Errorf("dao.GetUsersAddressReports: %s", err.Error())
+	}
+	if len(items) == 0 {
+		return bot.sendText(user, bot.dictionary.Get("t.not_have_addresses", user.Lang))
+	}
+	records := make([]subscriptionRecord, 0, len(items))
+	for _, item := range items {
+		records = append(records, subscriptionRecord{
+			Address: item.Address,
+			Alias:   item.Alias,
+			Type:    item.Type,
+		})
+	}
+	var data []byte
+	if format == exportFormatJSON {
+		data, err = json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return fmt.Errorf("json.MarshalIndent: %s", err.Error())
+		}
+	} else {
+		format = exportFormatCSV
+		buf := &bytes.Buffer{}
+		w := csv.NewWriter(buf)
+		_ = w.Write([]string{"address", "alias", "type"})
+		for _, r := range records {
+			_ = w.Write([]string{r.Address, r.Alias, r.Type})
+		}
+		w.Flush()
+		if err = w.Error(); err != nil {
+			return fmt.Errorf("csv.Write: %s", err.Error())
+		}
+		data = buf.Bytes()
+	}
+	msg := tgbotapi.NewDocumentUpload(user.TgID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("subscriptions.%s", format),
+		Bytes: data,
+	})
+	_, err = bot.api.Send(msg)
+	if err != nil {
+		return fmt.Errorf("api.Send: %s", err.Error())
+	}
+	return nil
+}
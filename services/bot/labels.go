@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"io/ioutil"
+	"os"
+)
+
+// labelsPath is the registry of known addresses (exchange wallets, contracts) as `{"<address>": "<label>"}`,
+// it overrides exchangeLabels and an empty label hides the default one.
+const labelsPath = "./labels.json"
+
+// exchangeLabels name the exchange wallets out of the box. Only add the addresses published by the exchange
+// or confirmed on the explorer, a wrong label on a transfer is worse than none.
+var exchangeLabels = map[string]string{}
+
+// contractLabels name the contracts the bot works with, the registry may override them.
+var contractLabels = map[string]string{
+	StakingContract:  "t.label_staking_contract",
+	node.NAXContract: "t.label_nax_contract",
+}
+
+func (bot *Bot) setLabels() error {
+	labels, err := readLabels(labelsPath)
+	if err != nil {
+		return fmt.Errorf("readLabels: %s", err.Error())
+	}
+	bot.mu.Lock()
+	bot.labels = labels
+	bot.mu.Unlock()
+	return nil
+}
+
+// readLabels merges the registry over exchangeLabels, the registry is optional.
+func readLabels(path string) (map[string]string, error) {
+	labels := make(map[string]string, len(exchangeLabels))
+	for address, label := range exchangeLabels {
+		labels[address] = label
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Info("Bot: readLabels: %s not found, using %d default labels", path, len(labels))
+			return labels, nil
+		}
+		return nil, fmt.Errorf("ioutil.ReadFile: %s", err.Error())
+	}
+	registry := make(map[string]string)
+	err = json.Unmarshal(data, &registry)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+	for address, label := range registry {
+		if label == "" {
+			delete(labels, address)
+			continue
+		}
+		labels[address] = label
+	}
+	return labels, nil
+}
+
+// userAliases returns a copy of the aliases of the user subscriptions by address.
+func (bot *Bot) userAliases(user models.User) map[string]string {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	aliases := make(map[string]string, len(bot.aliases[user.ID]))
+	for address, alias := range bot.aliases[user.ID] {
+		aliases[address] = alias
+	}
+	return aliases
+}
+
+// setAlias keeps the in-memory aliases in sync with the subscriptions, bot.mu must be held.
+func (bot *Bot) setAlias(userID uint64, address string, alias string) {
+	if alias == "" {
+		delete(bot.aliases[userID], address)
+		return
+	}
+	_, ok := bot.aliases[userID]
+	if !ok {
+		bot.aliases[userID] = make(map[string]string)
+	}
+	bot.aliases[userID][address] = alias
+}
+
+// addressLabel resolves the address by the user aliases, the validator accounts, the registry and the known contracts,
+// empty when the address is unknown.
+func (bot *Bot) addressLabel(address string, aliases map[string]string, lang string) string {
+	alias, ok := aliases[address]
+	if ok {
+		return alias
+	}
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	n, ok := bot.nodes[bot.nodeAccounts[address]]
+	if ok {
+		roles := []struct {
+			account string
+			key     string
+		}{
+			{n.Accounts.StakingAccount, "t.label_staking_account"},
+			{n.Accounts.ConsensusManager, "t.label_consensus_manager"},
+			{n.Accounts.GovManager, "t.label_gov_manager"},
+			{n.Accounts.Registrant, "t.label_registrant"},
+		}
+		for _, role := range roles {
+			if role.account == address {
+				return fmt.Sprintf(bot.dictionary.Get(role.key, lang), nodeTitle(n))
+			}
+		}
+	}
+	label, ok := bot.labels[address]
+	if ok {
+		return label
+	}
+	key, ok := contractLabels[address]
+	if ok {
+		return bot.dictionary.Get(key, lang)
+	}
+	return ""
+}
+
+// transferLabelsText returns the `from → to` line of the transfer notification,
+// empty when both addresses are unknown.
+func (bot *Bot) transferLabelsText(user models.User, aliases map[string]string, from string, to string) string {
+	fromLabel := bot.addressLabel(from, aliases, user.Lang)
+	toLabel := bot.addressLabel(to, aliases, user.Lang)
+	if fromLabel == "" && toLabel == "" {
+		return ""
+	}
+	if fromLabel == "" {
+		fromLabel = shortAddress(from)
+	}
+	if toLabel == "" {
+		toLabel = shortAddress(to)
+	}
+	return fmt.Sprintf("%s → %s\n", fromLabel, toLabel)
+}
+
+func shortAddress(address string) string {
+	if len(address) <= 12 {
+		return address
+	}
+	return address[:6] + "…" + address[len(address)-4:]
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadLabels(t *testing.T) {
+	defaults := exchangeLabels
+	defer func() { exchangeLabels = defaults }()
+	exchangeLabels = map[string]string{"n1hot": "Exchange hot wallet", "n1cold": "Exchange cold wallet"}
+
+	dir, err := ioutil.TempDir("", "labels")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "labels.json")
+
+	labels, err := readLabels(path)
+	if err != nil {
+		t.Fatalf("readLabels without the registry: %s", err.Error())
+	}
+	if !reflect.DeepEqual(labels, exchangeLabels) {
+		t.Errorf("readLabels without the registry = %v, want %v", labels, exchangeLabels)
+	}
+
+	err = ioutil.WriteFile(path, []byte(`{"n1hot": "Hot wallet", "n1cold": "", "n1other": "Other"}`), 0644)
+	if err != nil {
+		t.Fatalf("ioutil.WriteFile: %s", err.Error())
+	}
+	labels, err = readLabels(path)
+	if err != nil {
+		t.Fatalf("readLabels: %s", err.Error())
+	}
+	want := map[string]string{"n1hot": "Hot wallet", "n1other": "Other"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("readLabels = %v, want %v", labels, want)
+	}
+	if exchangeLabels["n1cold"] == "" {
+		t.Error("readLabels changed the defaults")
+	}
+
+	err = ioutil.WriteFile(path, []byte(`[`), 0644)
+	if err != nil {
+		t.Fatalf("ioutil.WriteFile: %s", err.Error())
+	}
+	_, err = readLabels(path)
+	if err == nil {
+		t.Error("readLabels of the broken registry error = nil")
+	}
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"time"
+)
+
+// offlineGracePeriod is how long a node may stay offline before users are alerted.
+const offlineGracePeriod = time.Minute * 10
+
+type nodeChange struct {
+	node   node.ValidatorNode
+	prev   node.ValidatorNode
+	status string
+}
+
+// diffNode compares the refreshed node with its previous state, bot.mu must be held.
+func (bot *Bot) diffNode(prev node.ValidatorNode, known bool, n node.ValidatorNode, now time.Time) (changes []nodeChange) {
+	if !n.Online {
+		since, ok := bot.offlineSince[n.ID]
+		if !ok {
+			since = now
+			bot.offlineSince[n.ID] = now
+			if !known {
+				// the node was offline before the restart, so it must have been reported already
+				bot.offlineNotified[n.ID] = struct{}{}
+			}
+		}
+		_, notified := bot.offlineNotified[n.ID]
+		if !notified && now.Sub(since) >= offlineGracePeriod {
+			bot.offlineNotified[n.ID] = struct{}{}
+			changes = append(changes, nodeChange{node: n, prev: prev, status: webhook.NodeStatusOffline})
+		}
+	} else {
+		_, notified := bot.offlineNotified[n.ID]
+		if notified {
+			changes = append(changes, nodeChange{node: n, prev: prev, status: webhook.NodeStatusOnline})
+		}
+		delete(bot.offlineSince, n.ID)
+		delete(bot.offlineNotified, n.ID)
+	}
+	if !known {
+		return changes
+	}
+	if prev.Approved != n.Approved {
+		status := webhook.NodeStatusUnapproved
+		if n.Approved {
+			status = webhook.NodeStatusApproved
+		}
+		changes = append(changes, nodeChange{node: n, prev: prev, status: status})
+	}
+	if prev.Type != n.Type {
+		changes = append(changes, nodeChange{node: n, prev: prev, status: webhook.NodeStatusTypeChanged})
+	}
+	return changes
+}
+
+func (bot *Bot) nodeChangeText(change nodeChange, lang string) string {
+	n := change.node
+	switch change.status {
+	case webhook.NodeStatusOffline:
+		return fmt.Sprintf(bot.dictionary.Get("t.node_went_offline", lang), nodeTitle(n), int(offlineGracePeriod.Minutes()))
+	case webhook.NodeStatusOnline:
+		return fmt.Sprintf(bot.dictionary.Get("t.node_back_online", lang), nodeTitle(n))
+	case webhook.NodeStatusApproved:
+		return fmt.Sprintf(bot.dictionary.Get("t.node_approved", lang), nodeTitle(n))
+	case webhook.NodeStatusUnapproved:
+		return fmt.Sprintf(bot.dictionary.Get("t.node_unapproved", lang), nodeTitle(n))
+	case webhook.NodeStatusTypeChanged:
+		return fmt.Sprintf(
+			bot.dictionary.Get("t.node_type_changed", lang),
+			nodeTitle(n),
+			bot.nodeTypeName(change.prev.Type, lang),
+			bot.nodeTypeName(n.Type, lang),
+		)
+	case webhook.NodeStatusRemoved:
+		return fmt.Sprintf(bot.dictionary.Get("t.node_removed", lang), nodeTitle(n))
+	}
+	return ""
+}
+
+// notifyNodeChanges alerts the node watchers and sends webhooks to the node accounts.
+func (bot *Bot) notifyNodeChanges(changes []nodeChange) {
+	var messages []tgbotapi.MessageConfig
+	bot.mu.RLock()
+	for _, change := range changes {
+		for _, user := range bot.nodeUsers(change.node) {
+			if user.Mute {
+				continue
+			}
+			messages = append(messages, tgbotapi.NewMessage(user.TgID, bot.nodeChangeText(change, user.Lang)))
+		}
+	}
+	bot.mu.RUnlock()
+
+	for _, change := range changes {
+		bot.sendNodeWebhooks(change.node.ID, nodeAddresses(change.node), webhook.Event{
+			Event: webhook.EventNodeStatus,
+			Data: webhook.NodeStatusData{
+				NodeID: change.node.ID,
+				Status: change.status,
+				Type:   change.node.Type,
+			},
+		})
+	}
+
+	for _, msg := range messages {
+		err := bot.sendMsg(msg)
+		if err != nil {
+			log.Error("Bot: notifyNodeChanges: api.Send: %s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"sort"
+	"strings"
+	"time"
+)
+
+const nodesPageSize = 8
+
+func (bot *Bot) setNodes() error {
+	list, err := bot.node.GetNodesList()
+	if err != nil {
+		return fmt.Errorf("node.GetNodesList: %s", err.Error())
+	}
+	if len(list) == 0 {
+		// an empty list would look like every node disappeared, the previous list is kept
+		log.Warn("Bot: setNodes: node.GetNodesList: empty list")
+		return nil
+	}
+	now := time.Now()
+	var changes []nodeChange
+	nodes := make(map[string]node.ValidatorNode, len(list))
+	bot.mu.Lock()
+	for _, n := range list {
+		nodes[n.ID] = n
+		prev, known := bot.nodes[n.ID]
+		changes = append(changes, bot.diffNode(prev, known, n, now)...)
+	}
+	for id, prev := range bot.nodes {
+		_, ok := nodes[id]
+		if !ok {
+			changes = append(changes, nodeChange{node: prev, prev: prev, status: webhook.NodeStatusRemoved})
+			delete(bot.offlineSince, id)
+			delete(bot.offlineNotified, id)
+		}
+	}
+	bot.nodes = nodes
+	accounts := make(map[string]string)
+	for _, n := range bot.nodes {
+		for _, address := range nodeAddresses(n) {
+			accounts[address] = n.ID
+		}
+	}
+	bot.nodeAccounts = accounts
+	bot.mu.Unlock()
+	bot.notifyNodeChanges(changes)
+	return nil
+}
+
+func (bot *Bot) setNodeFollowers() error {
+	usersNodes, err := bot.dao.GetUsersNodes(filters.UsersNodes{})
+	if err != nil {
+		return fmt.Errorf("dao.GetUsersNodes: %s", err.Error())
+	}
+	for _, un := range usersNodes {
+		_, ok := bot.nodeFollowers[un.NodeID]
+		if !ok {
+			bot.nodeFollowers[un.NodeID] = make(map[uint64]struct{})
+		}
+		bot.nodeFollowers[un.NodeID][un.UserID] = struct{}{}
+		bot.setNodeWebhook(un)
+	}
+	return nil
+}
+
+// convertValidatorSubscriptions moves the validator address subscriptions to the node follows, the address
+// stays subscribed as an account. Nothing is converted while the nodes list is not loaded.
+func (bot *Bot) convertValidatorSubscriptions() error {
+	bot.mu.RLock()
+	loaded := len(bot.nodes) != 0
+	bot.mu.RUnlock()
+	if !loaded {
+		log.Warn("Bot: convertValidatorSubscriptions: nodes are not loaded")
+		return nil
+	}
+	usersAddresses, err := bot.dao.GetUsersAddresses(filters.UsersAddresses{})
+	if err != nil {
+		return fmt.Errorf("dao.GetUsersAddresses: %s", err.Error())
+	}
+	addresses, err := bot.dao.GetAddresses(filters.Addresses{})
+	if err != nil {
+		return fmt.Errorf("dao.GetAddresses: %s", err.Error())
+	}
+	addressesMap := make(map[uint64]string)
+	for _, address := range addresses {
+		addressesMap[address.ID] = address.Address
+	}
+	for _, ua := range usersAddresses {
+		if ua.Type != models.AddressTypeValidator {
+			continue
+		}
+		address := addressesMap[ua.AddressID]
+		bot.mu.RLock()
+		n, ok := bot.nodes[bot.nodeAccounts[address]]
+		bot.mu.RUnlock()
+		if ok {
+			err = bot.dao.CreateUserNode(models.UserNode{
+				UserID: ua.UserID,
+				NodeID: n.ID,
+				Alias:  n.Info.Name,
+			})
+			if err != nil && err.Error() != derrors.ErrDuplicate {
+				return fmt.Errorf("dao.CreateUserNode: %s", err.Error())
+			}
+		} else {
+			log.Warn("Bot: convertValidatorSubscriptions: %s is not a node account anymore", address)
+		}
+		ua.Type = models.AddressTypeAccount
+		err = bot.dao.UpdateUserAddress(ua)
+		if err != nil {
+			return fmt.Errorf("dao.UpdateUserAddress: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func nodeAddresses(n node.ValidatorNode) []string {
+	return getUniqStrings([]string{
+		n.Accounts.ConsensusManager,
+		n.Accounts.GovManager,
+		n.Accounts.Registrant,
+		n.Accounts.StakingAccount,
+	})
+}
+
+// nodeUsers returns users following the node, bot.mu must be held.
+func (bot *Bot) nodeUsers(n node.ValidatorNode) map[uint64]models.User {
+	users := make(map[uint64]models.User)
+	for userID := range bot.nodeFollowers[n.ID] {
+		user, ok := bot.users[userID]
+		if ok {
+			users[userID] = user
+		}
+	}
+	return users
+}
+
+func (bot *Bot) followNode(user models.User, nodeID string) error {
+	bot.mu.RLock()
+	n, ok := bot.nodes[nodeID]
+	bot.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("node %s not found", nodeID)
+	}
+	err := bot.dao.CreateUserNode(models.UserNode{
+		UserID: user.ID,
+		NodeID: nodeID,
+		Alias:  n.Info.Name,
+	})
+	if err != nil {
+		return err
+	}
+	bot.mu.Lock()
+	_, ok = bot.nodeFollowers[nodeID]
+	if !ok {
+		bot.nodeFollowers[nodeID] = make(map[uint64]struct{})
+	}
+	bot.nodeFollowers[nodeID][user.ID] = struct{}{}
+	bot.mu.Unlock()
+	err = bot.ensureVotesSnapshot(nodeID)
+	if err != nil {
+		log.Error("Bot: followNode: ensureVotesSnapshot(%s): %s", nodeID, err.Error())
+	}
+	return nil
+}
+
+func (bot *Bot) unfollowNode(user models.User, nodeID string) error {
+	err := bot.dao.DeleteUserNode(user.ID, nodeID)
+	if err != nil {
+		return fmt.Errorf("dao.DeleteUserNode: %s", err.Error())
+	}
+	bot.mu.Lock()
+	delete(bot.nodeFollowers[nodeID], user.ID)
+	delete(bot.nodeWebhooks[nodeID], user.ID)
+	bot.mu.Unlock()
+	return nil
+}
+
+// findNodes returns nodes whose name or ID contains the query, sorted by name.
+func (bot *Bot) findNodes(query string) (list []node.ValidatorNode) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	bot.mu.RLock()
+	for _, n := range bot.nodes {
+		if query == "" ||
+			strings.Contains(strings.ToLower(n.Info.Name), query) ||
+			strings.Contains(strings.ToLower(n.ID), query) {
+			list = append(list, n)
+		}
+	}
+	bot.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Info.Name) < strings.ToLower(list[j].Info.Name)
+	})
+	return list
+}
+
+// sendNodePicker shows a page of nodes matching the query, the message is edited when messageID is set.
+func (bot *Bot) sendNodePicker(user models.User, query string, page int, messageID int) error {
+	list := bot.findNodes(query)
+	if len(list) == 0 {
+		return bot.sendText(user, bot.dictionary.Get("t.validators_not_found", user.Lang))
+	}
+	pages := (len(list) + nodesPageSize - 1) / nodesPageSize
+	if page < 0 || page >= pages {
+		page = 0
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, n := range list[page*nodesPageSize : minInt((page+1)*nodesPageSize, len(list))] {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(nodeTitle(n), fmt.Sprintf("follow_%s", n.ID)),
+		))
+	}
+	if nav := paginationRow("nodes", page, pages); len(nav) != 0 {
+		rows = append(rows, nav)
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	text := fmt.Sprintf(bot.dictionary.Get("t.choose_validator", user.Lang), len(list), page+1, pages)
+	if messageID != 0 {
+		msg := tgbotapi.NewEditMessageText(user.TgID, messageID, text)
+		msg.ReplyMarkup = &markup
+		_, err := bot.api.Send(msg)
+		if err != nil {
+			return fmt.Errorf("api.Send: %s", err.Error())
+		}
+		return nil
+	}
+	msg := tgbotapi.NewMessage(user.TgID, text)
+	msg.ReplyMarkup = markup
+	_, err := bot.api.Send(msg)
+	if err != nil {
+		return fmt.Errorf("api.Send: %s", err.Error())
+	}
+	return nil
+}
+
+// showNodeSubscriptions sends a card per followed node, returns the number of cards.
+func (bot *Bot) showNodeSubscriptions(user models.User) (int, error) {
+	usersNodes, err := bot.dao.GetUsersNodes(filters.UsersNodes{UserIDs: []uint64{user.ID}})
+	if err != nil {
+		return 0, fmt.Errorf("dao.GetUsersNodes: %s", err.Error())
+	}
+	for _, un := range usersNodes {
+		bot.mu.RLock()
+		n, ok := bot.nodes[un.NodeID]
+		bot.mu.RUnlock()
+		var text string
+		if ok {
+			text = bot.nodeCardText(n, user.Lang)
+		} else {
+			text = fmt.Sprintf(bot.dictionary.Get("t.node_unknown", user.Lang), un.Alias, un.NodeID)
+		}
+		var keyboard = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.voters", user.Lang), fmt.Sprintf("voters_%s", un.NodeID)),
+				tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.webhook", user.Lang), fmt.Sprintf("nodewebhook_%s", un.NodeID)),
+				tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.unfollow", user.Lang), fmt.Sprintf("unfollow_%s", un.NodeID)),
+			),
+		)
+		msg := tgbotapi.NewMessage(user.TgID, text)
+		msg.ReplyMarkup = keyboard
+		_, err := bot.api.Send(msg)
+		if err != nil {
+			return 0, fmt.Errorf("api.Send: %s", err.Error())
+		}
+	}
+	return len(usersNodes), nil
+}
+
+func (bot *Bot) nodeCardText(n node.ValidatorNode, lang string) string {
+	status := bot.dictionary.Get("t.node_offline", lang)
+	if n.Online {
+		status = bot.dictionary.Get("t.node_online", lang)
+	}
+	if !n.Approved {
+		status = fmt.Sprintf("%s, %s", status, bot.dictionary.Get("t.node_not_approved", lang))
+	}
+	return fmt.Sprintf(
+		bot.dictionary.Get("t.node_subscription", lang),
+		n.Info.Name,
+		n.ID,
+		bot.nodeTypeName(n.Type, lang),
+		status,
+		n.VoteValue.Div(node.PrecisionDivNAX).Truncate(4).String(),
+		n.StakingValue.Div(node.PrecisionDivNAS).Truncate(4).String(),
+		n.StabilityIndex,
+	)
+}
+
+func (bot *Bot) nodeTypeName(t int, lang string) string {
+	switch t {
+	case consensusNode:
+		return bot.dictionary.Get("t.node_type_consensus", lang)
+	case candidateNode:
+		return bot.dictionary.Get("t.node_type_candidate", lang)
+	}
+	return bot.dictionary.Get("t.node_type_other", lang)
+}
+
+func nodeTitle(n node.ValidatorNode) string {
+	if n.Info.Name == "" {
+		return n.ID
+	}
+	return fmt.Sprintf("%s (%s)", n.Info.Name, n.ID)
+}
+
+// paginationRow builds prev/next buttons with `<prefix>_<page>` callback data.
+func paginationRow(prefix string, page int, pages int) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("%s_%d", prefix, page-1)))
+	}
+	if page < pages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("%s_%d", prefix, page+1)))
+	}
+	return row
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
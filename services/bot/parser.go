@@ -1,23 +1,17 @@
 package bot
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"github.com/everstake/nebulas-tg-bot/log"
 	"github.com/everstake/nebulas-tg-bot/models"
 	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
-	"github.com/shopspring/decimal"
 	"strconv"
-	"time"
 )
 
-const pollingCycleBlocks = 210
-const blocksInGovernancePeriod = 820
 const candidateNode = 3
 const consensusNode = 2
-const startPointBlock = 4893100 // 23348  polling cycle
 const BlockedByUserErr = "Forbidden: bot was blocked by the user"
 
 func (bot *Bot) Parsing() {
@@ -51,41 +45,59 @@ func (bot *Bot) Parsing() {
 					bot.checkStabilityIndexes()
 				}
 
-				if (h-startPointBlock)%(pollingCycleBlocks*blocksInGovernancePeriod) == 0 {
-					bot.notifyGovernanceCandidates()
+				if h%votesSnapshotBlocks == 0 {
+					bot.snapshotVotes()
+					bot.sendRewardsDigest()
+					bot.pruneStabilityIndexes()
+				}
+
+				err = bot.trackGovernance(h)
+				if err != nil {
+					return fmt.Errorf("trackGovernance: %s", err.Error())
 				}
 
 				block, err := bot.node.GetBlock(h)
 				if err != nil {
 					return fmt.Errorf("node.GetBlock(%d): %s", h, err.Error())
 				}
+				bot.trackProduction(block)
 				for _, tx := range block.Result.Transactions {
-					if tx.To == StakingContract {
-						err = bot.stakingNotify(tx)
-						if err != nil {
-							return fmt.Errorf("stakingNotify (height: %d): %s", h, err.Error())
-						}
-						continue
-					}
-					if bot.addressExist(tx.From) {
-						bot.txNotify(tx.From, tx)
-					}
-					if bot.addressExist(tx.To) {
-						bot.txNotify(tx.To, tx)
-					}
+					bot.invalidateTxStates(h, tx)
+					bot.trackGovernanceTx(h, tx)
+					bot.notifyTx(h, tx)
+					bot.settlePending(tx)
 				}
 				state.Value = fmt.Sprintf("%d", h)
 				err = bot.dao.UpdateState(state)
 				if err != nil {
 					log.Error("Bot: Parsing: dao.UpdateState: %s", err.Error())
 				}
+				bot.setParsedHeight(h)
 			}
 			return nil
 		}()
 		if err != nil {
 			log.Error("Bot Parsing: %s", err.Error())
 		}
-		<-time.After(time.Second * 2)
+		bot.waitBlock(err != nil)
+	}
+}
+
+// notifyTx sends the notifications of a parsed transaction.
+func (bot *Bot) notifyTx(h uint64, tx node.Transaction) {
+	if bot.rewardNotify(h, tx) {
+		return
+	}
+	bot.contractNotify(tx)
+	if tx.To == StakingContract {
+		bot.stakingNotify(tx)
+		return
+	}
+	if bot.addressExist(tx.From) {
+		bot.txNotify(tx.From, tx)
+	}
+	if bot.addressExist(tx.To) {
+		bot.txNotify(tx.To, tx)
 	}
 }
 
@@ -102,43 +114,51 @@ func (bot *Bot) txNotify(address string, tx node.Transaction) {
 		if !ok {
 			continue
 		}
-		users[userID] = user
-	}
-	for userID := range bot.validators[address] {
-		user, ok := bot.users[userID]
-		if !ok {
+		if bot.watchesCalls(userID, address, tx) {
 			continue
 		}
 		users[userID] = user
 	}
 	bot.mu.RUnlock()
+	bot.sendWebhooks([]string{address}, webhook.Event{
+		Event: webhook.EventTransfer,
+		Data: webhook.TransferData{
+			Token:       "NAS",
+			From:        tx.From,
+			To:          tx.To,
+			Value:       value,
+			Transaction: tx,
+		},
+	})
 	for _, user := range users {
 		if user.Mute {
 			continue
 		}
-		txt := fmt.Sprintf(
-			bot.dictionary.Get("t.transaction", user.Lang),
-			tx.Hash,
-			tx.From,
-			tx.To,
-			value.Truncate(4).String(),
-			tx.BlockHeight,
-			status,
-			tx.GasLimit.String(),
-			tx.GasUsed.String(),
-			tx.Nonce,
-			tx.Type,
-			time.Unix(tx.Timestamp, 0).String(),
-		)
-
 		if value.GreaterThan(user.MinThreshold) && value.LessThanOrEqual(user.MaxThreshold) {
+			txt := bot.transactionText(user, tx, value, status)
 			url := fmt.Sprintf("https://explorer.nebulas.io/#/tx/%s", tx.Hash)
 			var keyboard = tgbotapi.NewInlineKeyboardMarkup(
 				tgbotapi.NewInlineKeyboardRow(
 					tgbotapi.NewInlineKeyboardButtonURL(bot.dictionary.Get("b.link", user.Lang), url),
 				),
 			)
+			pending, ok := bot.takePendingMessage(tx.Hash, user)
+			if ok {
+				header := "t.tx_confirmed"
+				if tx.Status != 1 {
+					header = "t.tx_failed"
+				}
+				edit := tgbotapi.NewEditMessageText(pending.tgID, pending.messageID, bot.dictionary.Get(header, user.Lang)+"\n\n"+txt)
+				edit.ParseMode = tgbotapi.ModeHTML
+				edit.ReplyMarkup = &keyboard
+				_, err := bot.api.Send(edit)
+				if err != nil {
+					log.Error("Bot: txNotify: %s", err.Error())
+				}
+				continue
+			}
 			msg := tgbotapi.NewMessage(user.TgID, txt)
+			msg.ParseMode = tgbotapi.ModeHTML
 			msg.ReplyMarkup = keyboard
 			_, err := bot.api.Send(msg)
 			if err != nil {
@@ -148,251 +168,140 @@ func (bot *Bot) txNotify(address string, tx node.Transaction) {
 	}
 }
 
-func (bot *Bot) stakingNotify(tx node.Transaction) error {
+func (bot *Bot) stakingNotify(tx node.Transaction) {
 	if tx.Status != 1 {
-		return nil
+		return
 	}
-	data, err := base64.StdEncoding.DecodeString(tx.Data)
+	call, err := node.DecodeStakingCall(tx.Data)
 	if err != nil {
-		return fmt.Errorf("base64.DecodeString: %s", err.Error())
+		log.Warn("Bot: stakingNotify: node.DecodeStakingCall(%s): %s", tx.Hash, err.Error())
+		return
 	}
-	var contract node.CallContract
-	err = json.Unmarshal(data, &contract)
-	if err != nil {
-		return fmt.Errorf("json.Unmarshal: %s", err.Error())
+	switch call.Kind {
+	case node.StakingVote, node.StakingCancelVote:
+		bot.voteNotify(tx, call)
+	case node.StakingTransfer:
+		bot.naxTransferNotify(tx, call)
+	default:
+		bot.stakingEventNotify(tx, call)
 	}
+}
 
-	switch contract.Function {
-	case "cancelVote", "vote":
-		var args []string
-		err = json.Unmarshal([]byte(contract.Args), &args)
-		if err != nil {
-			return fmt.Errorf("json.Unmarshal: %s", err.Error())
-		}
-		if len(args) < 2 {
-			return nil
-		}
-		nodeID := args[0]
-		bot.mu.RLock()
-		validator, ok := bot.nodes[nodeID]
-		if !ok {
-			log.Warn("Bot: Parser: validator %s not found", nodeID)
-			return nil
-		}
-		value, err := decimal.NewFromString(args[1])
-		if err != nil {
-			log.Warn("Bot: Parser: decimal.NewFromString: %s", err.Error())
-			return nil
-		}
-		value = value.Div(node.PrecisionDivNAX)
-		addresses := getUniqStrings([]string{
-			validator.Accounts.ConsensusManager,
-			validator.Accounts.GovManager,
-			validator.Accounts.Registrant,
-			validator.Accounts.StakingAccount,
-		})
-		users := make(map[uint64]models.User)
-		for _, address := range addresses {
-			validators, ok := bot.validators[address]
-			if !ok {
-				continue
-			}
-			for userID := range validators {
-				user, ok := bot.users[userID]
-				if ok {
-					users[userID] = user
-				}
-			}
-		}
-		userIDs, ok := bot.addresses[tx.From]
-		if ok {
-			for userID := range userIDs {
-				user, ok := bot.users[userID]
-				if ok {
-					users[userID] = user
-				}
-			}
-		}
+func (bot *Bot) voteNotify(tx node.Transaction, call node.StakingCall) {
+	if call.NodeID == "" || call.Value.IsZero() {
+		return
+	}
+	nodeID := call.NodeID
+	value := call.Amount()
+	bot.mu.RLock()
+	validator, ok := bot.nodes[nodeID]
+	if !ok {
 		bot.mu.RUnlock()
-		for _, user := range users {
-			if user.Mute {
-				continue
-			}
-			var text string
-			if contract.Function == "vote" {
-				text = fmt.Sprintf(
-					bot.dictionary.Get("t.new_delegation", user.Lang),
-					tx.From,
-					nodeID,
-					value.String(),
-				)
-			} else {
-				text = fmt.Sprintf(
-					bot.dictionary.Get("t.new_undelegation", user.Lang),
-					tx.From,
-					nodeID,
-					value.String(),
-				)
-			}
-			msg := tgbotapi.NewMessage(user.TgID, text)
-			err := bot.sendMsg(msg)
-			if err != nil {
-				return fmt.Errorf("api.Send: %s", err.Error())
+		log.Warn("Bot: voteNotify: validator %s not found", nodeID)
+		return
+	}
+	addresses := nodeAddresses(validator)
+	followers := bot.nodeUsers(validator)
+	users := make(map[uint64]models.User, len(followers))
+	for userID, user := range followers {
+		users[userID] = user
+	}
+	userIDs, ok := bot.addresses[tx.From]
+	if ok {
+		for userID := range userIDs {
+			user, ok := bot.users[userID]
+			if ok {
+				users[userID] = user
 			}
 		}
-	case "transfer":
-		var args []string
-		err = json.Unmarshal([]byte(contract.Args), &args)
-		if err != nil {
-			return fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+	bot.mu.RUnlock()
+	event := webhook.EventVote
+	if call.Kind == node.StakingCancelVote {
+		event = webhook.EventCancelVote
+	}
+	bot.sendNodeWebhooks(nodeID, append(addresses, tx.From), webhook.Event{
+		Event: event,
+		Data: webhook.VoteData{
+			NodeID:      nodeID,
+			Value:       value,
+			Transaction: tx,
+		},
+	})
+	for _, user := range users {
+		if user.Mute {
+			continue
 		}
-		if len(args) < 2 {
-			return nil
+		key := "t.new_delegation"
+		if call.Kind == node.StakingCancelVote {
+			key = "t.new_undelegation"
 		}
-		to := args[0]
-		value, err := decimal.NewFromString(args[1])
+		text := fmt.Sprintf(
+			bot.dictionary.Get(key, user.Lang),
+			tx.From,
+			nodeID,
+			value.String(),
+		)
+		msg := tgbotapi.NewMessage(user.TgID, text)
+		err := bot.sendMsg(msg)
 		if err != nil {
-			log.Warn("Bot: Parser: decimal.NewFromString: %s", err.Error())
-			return nil
-		}
-		value = value.Div(node.PrecisionDivNAX)
-		users := make(map[uint64]models.User)
-		bot.mu.RLock()
-		userIDs, ok := bot.addresses[tx.From]
-		if ok {
-			for userID := range userIDs {
-				user, ok := bot.users[userID]
-				if ok {
-					users[user.ID] = user
-				}
-			}
-		}
-		userIDs, ok = bot.addresses[to]
-		if ok {
-			for userID := range userIDs {
-				user, ok := bot.users[userID]
-				if ok {
-					users[user.ID] = user
-				}
-			}
-		}
-		bot.mu.RUnlock()
-		for _, user := range users {
-			text := fmt.Sprintf(
-				bot.dictionary.Get("t.transfer_nax", user.Lang),
-				tx.From,
-				to,
-				value,
-			)
-			msg := tgbotapi.NewMessage(user.TgID, text)
-			err := bot.sendMsg(msg)
-			if err != nil {
-				return fmt.Errorf("api.Send: %s", err.Error())
-			}
+			log.Error("Bot: voteNotify: api.Send: %s", err.Error())
 		}
 	}
-	return nil
-}
-
-func (bot *Bot) setNodes() error {
-	list, err := bot.node.GetNodesList()
-	if err != nil {
-		return fmt.Errorf("node.GetNodesList: %s", err.Error())
-	}
-	for _, n := range list {
-		bot.nodes[n.ID] = n
+	if call.Kind == node.StakingCancelVote {
+		bot.notifyWhaleCancel(followers, nodeID, tx.From, value)
 	}
-	return nil
 }
 
-func (bot *Bot) checkStabilityIndexes() {
-	messages := make(map[int64]string)
+func (bot *Bot) naxTransferNotify(tx node.Transaction, call node.StakingCall) {
+	if call.Address == "" {
+		return
+	}
+	to := call.Address
+	value := call.Amount()
+	users := make(map[uint64]models.User)
 	bot.mu.RLock()
-	for _, n := range bot.nodes {
-		if n.StabilityIndex != 1 {
-			prev, ok := bot.lastStabilityIndexes[n.ID]
-			if !ok {
-				continue
-			}
-			if n.StabilityIndex < prev {
-				addresses := getUniqStrings([]string{
-					n.Accounts.ConsensusManager,
-					n.Accounts.GovManager,
-					n.Accounts.Registrant,
-					n.Accounts.StakingAccount,
-				})
-				for _, address := range addresses {
-					usersIDs, ok := bot.validators[address]
-					if !ok {
-						continue
-					}
-					for userID := range usersIDs {
-						user, ok := bot.users[userID]
-						if !ok {
-							continue
-						}
-						if user.Mute {
-							continue
-						}
-						text := fmt.Sprintf(bot.dictionary.Get("t.changed_stability_index", user.Lang), n.ID, n.StabilityIndex)
-						messages[user.TgID] = text
-					}
-				}
+	userIDs, ok := bot.addresses[tx.From]
+	if ok {
+		for userID := range userIDs {
+			user, ok := bot.users[userID]
+			if ok {
+				users[user.ID] = user
 			}
 		}
 	}
-	for _, n := range bot.nodes {
-		bot.lastStabilityIndexes[n.ID] = n.StabilityIndex
-	}
-	bot.mu.RUnlock()
-
-	// send messages
-	for tgID, text := range messages {
-		msg := tgbotapi.NewMessage(tgID, text)
-		_, err := bot.api.Send(msg)
-		if err != nil {
-			log.Error("Bot: checkStabilityIndexes: api.Send: %s", err.Error())
-		}
-	}
-}
-
-func (bot *Bot) notifyGovernanceCandidates() {
-	messages := make(map[int64]string)
-	bot.mu.RLock()
-	for _, n := range bot.nodes {
-		if n.Type == consensusNode || n.Type == candidateNode {
-			addresses := getUniqStrings([]string{
-				n.Accounts.ConsensusManager,
-				n.Accounts.GovManager,
-				n.Accounts.Registrant,
-				n.Accounts.StakingAccount,
-			})
-			for _, address := range addresses {
-				usersIDs, ok := bot.validators[address]
-				if !ok {
-					continue
-				}
-				for userID := range usersIDs {
-					user, ok := bot.users[userID]
-					if !ok {
-						continue
-					}
-					if user.Mute {
-						continue
-					}
-					text := fmt.Sprintf(bot.dictionary.Get("t.inclusion_governance", user.Lang), n.ID)
-					messages[user.TgID] = text
-				}
+	userIDs, ok = bot.addresses[to]
+	if ok {
+		for userID := range userIDs {
+			user, ok := bot.users[userID]
+			if ok {
+				users[user.ID] = user
 			}
 		}
 	}
 	bot.mu.RUnlock()
-	// send messages
-	for tgID, text := range messages {
-		msg := tgbotapi.NewMessage(tgID, text)
-		_, err := bot.api.Send(msg)
+	bot.sendWebhooks([]string{tx.From, to}, webhook.Event{
+		Event: webhook.EventTransfer,
+		Data: webhook.TransferData{
+			Token:       "NAX",
+			From:        tx.From,
+			To:          to,
+			Value:       value,
+			Transaction: tx,
+		},
+	})
+	for _, user := range users {
+		aliases := bot.userAliases(user)
+		text := bot.transferLabelsText(user, aliases, tx.From, to) + fmt.Sprintf(
+			bot.dictionary.Get("t.transfer_nax", user.Lang),
+			tx.From,
+			to,
+			value,
+		)
+		msg := tgbotapi.NewMessage(user.TgID, text)
+		err := bot.sendMsg(msg)
 		if err != nil {
-			log.Error("Bot: notifyGovernanceCandidates: api.Send: %s", err.Error())
+			log.Error("Bot: naxTransferNotify: api.Send: %s", err.Error())
 		}
 	}
 }
@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"time"
+)
+
+// pendingTTL is how long a pending alert waits for the irreversible block before it is marked as dropped.
+const pendingTTL = time.Hour
+
+type (
+	pendingTx struct {
+		createdAt time.Time
+		messages  map[uint64]pendingMessage // [userID]
+		sending   bool                      // pendingNotify reserved the tx and is still sending the alerts
+		settled   bool                      // the tx was parsed while the alerts were being sent
+		status    int
+	}
+	pendingMessage struct {
+		tgID      int64
+		messageID int
+		lang      string
+		text      string
+	}
+)
+
+// watchPending polls the blocks between the latest irreversible block and the tail and alerts about
+// transactions of the subscribed addresses before Parsing sees them.
+func (bot *Bot) watchPending() {
+	var lastHeight uint64
+	interval := time.Second * time.Duration(bot.cfg.Pending.Interval)
+	for {
+		err := func() error {
+			state, err := bot.node.GetNebState()
+			if err != nil {
+				return fmt.Errorf("node.GetNebState: %s", err.Error())
+			}
+			lib, err := bot.node.GetLatestIrreversibleBlock()
+			if err != nil {
+				return fmt.Errorf("node.GetLatestIrreversibleBlock: %s", err.Error())
+			}
+			if lastHeight < lib.Result.Height {
+				lastHeight = lib.Result.Height
+			}
+			for h := lastHeight + 1; h <= state.Result.Height; h++ {
+				block, err := bot.node.GetBlock(h)
+				if err != nil {
+					return fmt.Errorf("node.GetBlock(%d): %s", h, err.Error())
+				}
+				for _, tx := range block.Result.Transactions {
+					// the same transactions Parsing hands to txNotify, the others would never be confirmed
+					if tx.To == StakingContract {
+						continue
+					}
+					if _, ok := bot.rewardCall(tx); ok {
+						continue
+					}
+					bot.pendingNotify(h, tx)
+				}
+				lastHeight = h
+			}
+			bot.expirePending()
+			return nil
+		}()
+		if err != nil {
+			log.Error("Bot: watchPending: %s", err.Error())
+		}
+		<-time.After(interval)
+	}
+}
+
+func (bot *Bot) pendingNotify(h uint64, tx node.Transaction) {
+	value := tx.Value.Div(node.PrecisionDivNAS)
+	bot.mu.Lock()
+	_, exists := bot.pending[tx.Hash]
+	if !exists {
+		// the reservation lets settlePending record the result of the tx parsed while the alerts are being sent
+		bot.pending[tx.Hash] = pendingTx{createdAt: time.Now(), sending: true}
+	}
+	users := bot.addressUsers(tx.From)
+	for userID, user := range bot.addressUsers(tx.To) {
+		if !bot.watchesCalls(userID, tx.To, tx) {
+			users[userID] = user
+		}
+	}
+	bot.mu.Unlock()
+	if exists {
+		return
+	}
+	messages := make(map[uint64]pendingMessage)
+	for _, user := range users {
+		if user.Mute || !value.GreaterThan(user.MinThreshold) || value.GreaterThan(user.MaxThreshold) {
+			continue
+		}
+		text := fmt.Sprintf(
+			bot.dictionary.Get("t.pending_transaction", user.Lang),
+			tx.Hash,
+			tx.From,
+			tx.To,
+			value.Truncate(4).String(),
+			h,
+		)
+		msg, err := bot.api.Send(tgbotapi.NewMessage(user.TgID, text))
+		if err != nil {
+			log.Error("Bot: pendingNotify: api.Send: %s", err.Error())
+			continue
+		}
+		messages[user.ID] = pendingMessage{
+			tgID:      user.TgID,
+			messageID: msg.MessageID,
+			lang:      user.Lang,
+			text:      text,
+		}
+	}
+	bot.mu.Lock()
+	p := bot.pending[tx.Hash]
+	if p.settled || len(messages) == 0 {
+		delete(bot.pending, tx.Hash)
+	} else {
+		p.sending = false
+		p.messages = messages
+		bot.pending[tx.Hash] = p
+	}
+	bot.mu.Unlock()
+	if p.settled {
+		bot.confirmPending(p.status, messages)
+	}
+}
+
+// takePendingMessage returns the pending alert sent to the user about the transaction and forgets it.
+func (bot *Bot) takePendingMessage(hash string, user models.User) (pendingMessage, bool) {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	p, ok := bot.pending[hash]
+	if !ok || p.sending {
+		return pendingMessage{}, false
+	}
+	msg, ok := p.messages[user.ID]
+	delete(p.messages, user.ID)
+	if len(p.messages) == 0 {
+		delete(bot.pending, hash)
+	}
+	return msg, ok
+}
+
+// expirePending marks alerts of transactions which never became irreversible as dropped.
+func (bot *Bot) expirePending() {
+	var expired []pendingMessage
+	bot.mu.Lock()
+	for hash, p := range bot.pending {
+		if p.sending || time.Since(p.createdAt) < pendingTTL {
+			continue
+		}
+		for _, msg := range p.messages {
+			expired = append(expired, msg)
+		}
+		delete(bot.pending, hash)
+	}
+	bot.mu.Unlock()
+	for _, msg := range expired {
+		edit := tgbotapi.NewEditMessageText(msg.tgID, msg.messageID, bot.dictionary.Get("t.pending_dropped", msg.lang))
+		err := bot.sendMsg(edit)
+		if err != nil {
+			log.Error("Bot: expirePending: api.Send: %s", err.Error())
+		}
+	}
+}
+
+// settlePending confirms the pending alerts of the parsed transaction that txNotify has not edited,
+// e.g. the user muted the notifications or changed the threshold meanwhile.
+func (bot *Bot) settlePending(tx node.Transaction) {
+	bot.mu.Lock()
+	p, ok := bot.pending[tx.Hash]
+	if ok && p.sending {
+		// pendingNotify confirms the alerts once they are sent
+		p.settled = true
+		p.status = tx.Status
+		bot.pending[tx.Hash] = p
+		ok = false
+	} else {
+		delete(bot.pending, tx.Hash)
+	}
+	bot.mu.Unlock()
+	if !ok {
+		return
+	}
+	bot.confirmPending(tx.Status, p.messages)
+}
+
+// confirmPending edits the pending alerts with the result of the parsed transaction.
+func (bot *Bot) confirmPending(status int, messages map[uint64]pendingMessage) {
+	header := "t.tx_confirmed"
+	if status != 1 {
+		header = "t.tx_failed"
+	}
+	for _, msg := range messages {
+		edit := tgbotapi.NewEditMessageText(msg.tgID, msg.messageID, bot.dictionary.Get(header, msg.lang)+"\n\n"+msg.text)
+		err := bot.sendMsg(edit)
+		if err != nil {
+			log.Error("Bot: confirmPending: api.Send: %s", err.Error())
+		}
+	}
+}
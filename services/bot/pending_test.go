@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/config"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+)
+
+// testTelegram answers the Bot API requests with a sent message and records them, onSend runs before
+// a sendMessage request is answered.
+type testTelegram struct {
+	requests []url.Values
+	methods  []string
+	onSend   func()
+}
+
+func (tg *testTelegram) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := req.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+	method := path.Base(req.URL.Path)
+	tg.methods = append(tg.methods, method)
+	tg.requests = append(tg.requests, req.PostForm)
+	if method == "sendMessage" && tg.onSend != nil {
+		tg.onSend()
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(`{"ok":true,"result":{"message_id":7}}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestPendingSettledWhileSending(t *testing.T) {
+	const address = "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"
+	bot, _ := newTestBot(config.Config{})
+	tg := &testTelegram{}
+	bot.api = &tgbotapi.BotAPI{Token: "test", Client: &http.Client{Transport: tg}}
+	bot.dictionary = models.Dictionary{
+		"t.pending_transaction": {"en": "pending %s %s %s %s %d"},
+		"t.tx_confirmed":        {"en": "confirmed"},
+	}
+	bot.users[1] = models.User{ID: 1, TgID: 10, Lang: "en", MinThreshold: decimal.Zero, MaxThreshold: decimal.New(1, 9)}
+	bot.addresses[address] = map[uint64]struct{}{1: {}}
+	tx := node.Transaction{Hash: "hash", From: address, To: "n1other", Value: node.PrecisionDivNAS, Status: 1}
+
+	// Parsing settles the tx after the alert is sent but before pendingNotify stores it
+	tg.onSend = func() {
+		bot.settlePending(tx)
+		if _, ok := bot.takePendingMessage(tx.Hash, bot.users[1]); ok {
+			t.Error("takePendingMessage returned the alert which is being sent")
+		}
+	}
+	bot.pendingNotify(1, tx)
+
+	if _, ok := bot.pending[tx.Hash]; ok {
+		t.Error("the settled tx is still pending and would expire as dropped")
+	}
+	want := []string{"sendMessage", "editMessageText"}
+	if strings.Join(tg.methods, ",") != strings.Join(want, ",") {
+		t.Fatalf("requests = %v, want %v", tg.methods, want)
+	}
+	edit := tg.requests[1]
+	if edit.Get("message_id") != "7" || !strings.HasPrefix(edit.Get("text"), "confirmed\n\npending hash") {
+		t.Errorf("editMessageText = %v", edit)
+	}
+}
+
+func TestPendingSettledAfterSending(t *testing.T) {
+	const address = "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"
+	bot, _ := newTestBot(config.Config{})
+	tg := &testTelegram{}
+	bot.api = &tgbotapi.BotAPI{Token: "test", Client: &http.Client{Transport: tg}}
+	bot.dictionary = models.Dictionary{"t.tx_failed": {"en": "failed"}}
+	bot.users[1] = models.User{ID: 1, TgID: 10, Lang: "en", MinThreshold: decimal.Zero, MaxThreshold: decimal.New(1, 9)}
+	bot.addresses[address] = map[uint64]struct{}{1: {}}
+	tx := node.Transaction{Hash: "hash", From: address, To: "n1other", Value: node.PrecisionDivNAS, Status: 0}
+
+	bot.pendingNotify(1, tx)
+	p, ok := bot.pending[tx.Hash]
+	if !ok || p.sending || len(p.messages) != 1 {
+		t.Fatalf("pending = %+v, %t", p, ok)
+	}
+	bot.pendingNotify(1, tx)
+	bot.settlePending(tx)
+
+	if _, ok := bot.pending[tx.Hash]; ok {
+		t.Error("the settled tx is still pending")
+	}
+	want := []string{"sendMessage", "editMessageText"}
+	if strings.Join(tg.methods, ",") != strings.Join(want, ",") {
+		t.Fatalf("requests = %v, want %v", tg.methods, want)
+	}
+	if !strings.HasPrefix(tg.requests[1].Get("text"), "failed\n\n") {
+		t.Errorf("editMessageText = %v", tg.requests[1])
+	}
+}
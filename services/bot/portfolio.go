@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+)
+
+const (
+	subscriptionsPageSize = 20
+	// subscriptionsWorkers limits the concurrent node requests of a subscriptions list.
+	subscriptionsWorkers = 10
+	maxPortfolioAliases  = 30
+)
+
+type portfolioItem struct {
+	alias string
+	nas   decimal.Decimal
+	nax   decimal.Decimal
+	value decimal.Decimal
+}
+
+// sendPortfolio sends the totals of the account subscriptions with a breakdown by alias,
+// nothing is sent when the user has no account subscriptions.
+func (bot *Bot) sendPortfolio(user models.User, states []models.AddressState) error {
+	nasPrice := bot.market.GetNASPrice()
+	naxPrice := bot.market.GetNAXPrice()
+	var total portfolioItem
+	var staked decimal.Decimal
+	var count, unavailable int
+	items := make(map[string]*portfolioItem) // [alias]
+	for _, state := range states {
+		if state.Type != models.AddressTypeAccount {
+			continue
+		}
+		if state.Unavailable {
+			unavailable++
+			continue
+		}
+		count++
+		value := state.NAS.Mul(nasPrice).Add(state.NAX.Add(state.VotedAmount).Mul(naxPrice))
+		total.nas = total.nas.Add(state.NAS)
+		total.nax = total.nax.Add(state.NAX)
+		total.value = total.value.Add(value)
+		staked = staked.Add(state.VotedAmount)
+		alias := state.Alias
+		if alias == "" {
+			alias = state.Address
+		}
+		item, ok := items[alias]
+		if !ok {
+			item = &portfolioItem{alias: alias}
+			items[alias] = item
+		}
+		item.nas = item.nas.Add(state.NAS)
+		item.nax = item.nax.Add(state.NAX.Add(state.VotedAmount))
+		item.value = item.value.Add(value)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	breakdown := make([]*portfolioItem, 0, len(items))
+	for _, item := range items {
+		breakdown = append(breakdown, item)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].value.Equal(breakdown[j].value) {
+			return breakdown[i].alias < breakdown[j].alias
+		}
+		return breakdown[i].value.GreaterThan(breakdown[j].value)
+	})
+	var lines []string
+	for i, item := range breakdown {
+		if i == maxPortfolioAliases {
+			lines = append(lines, fmt.Sprintf(bot.dictionary.Get("t.portfolio_more", user.Lang), len(breakdown)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf(
+			bot.dictionary.Get("t.portfolio_item", user.Lang),
+			item.alias,
+			item.nas.Truncate(4).String(),
+			item.nax.Truncate(4).String(),
+			bot.fiatValue(item.value, user.Currency, 2),
+		))
+	}
+	if unavailable != 0 {
+		lines = append(lines, "", fmt.Sprintf(bot.dictionary.Get("t.portfolio_unavailable", user.Lang), unavailable))
+	}
+	text := fmt.Sprintf(
+		bot.dictionary.Get("t.portfolio", user.Lang),
+		count,
+		total.nas.Truncate(4).String(),
+		bot.fiatValue(total.nas.Mul(nasPrice), user.Currency, 2),
+		total.nax.Truncate(4).String(),
+		bot.fiatValue(total.nax.Mul(naxPrice), user.Currency, 2),
+		staked.Truncate(4).String(),
+		bot.fiatValue(staked.Mul(naxPrice), user.Currency, 2),
+		bot.fiatValue(total.value, user.Currency, 2),
+		strings.Join(lines, "\n"),
+	)
+	return bot.sendText(user, text+bot.stalePricesText(user.Lang))
+}
+
+func (bot *Bot) sendSubscriptionsPager(user models.User, page int, pages int, from int, to int, total int) error {
+	msg := tgbotapi.NewMessage(user.TgID, fmt.Sprintf(bot.dictionary.Get("t.subscriptions_page", user.Lang), from, to, total))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(paginationRow("subs", page, pages))
+	return bot.sendMsg(msg)
+}
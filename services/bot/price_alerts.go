@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/market"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxPriceAlerts = 10
+	// change alerts are evaluated on the market ticks, so the window can not be shorter than a tick
+	minPriceAlertWindow = 5
+	maxPriceAlertWindow = 24 * 60
+	priceAlertRepeat    = "repeat"
+	pricePlaces         = 8
+)
+
+type pricePoint struct {
+	price decimal.Decimal
+	time  time.Time
+}
+
+// checkPriceAlerts is called on every market tick. One-shot alerts are removed once triggered,
+// recurring ones are disarmed until the price is back on the other side of the level
+// (or the window has passed for change alerts).
+func (bot *Bot) checkPriceAlerts() {
+	now := time.Now()
+	prices := make(map[string]decimal.Decimal)
+	bot.mu.Lock()
+	for _, asset := range []string{market.AssetNAS, market.AssetNAX} {
+		// a failed refresh keeps the previous price, alerts are not evaluated on it
+		updated := bot.market.LastUpdated(asset)
+		if now.Sub(updated) > market.StaleAfter {
+			continue
+		}
+		prices[asset] = bot.market.GetPrice(asset)
+		history := bot.priceHistory[asset]
+		if len(history) == 0 || history[len(history)-1].time.Before(updated) {
+			history = append(history, pricePoint{price: prices[asset], time: updated})
+		}
+		for len(history) > 0 && now.Sub(history[0].time) > time.Duration(maxPriceAlertWindow+minPriceAlertWindow)*time.Minute {
+			history = history[1:]
+		}
+		bot.priceHistory[asset] = history
+	}
+	bot.mu.Unlock()
+
+	alerts, err := bot.dao.GetPriceAlerts(filters.PriceAlerts{})
+	if err != nil {
+		log.Error("Bot: checkPriceAlerts: dao.GetPriceAlerts: %s", err.Error())
+		return
+	}
+	for _, alert := range alerts {
+		usdPrice := prices[alert.Asset]
+		if usdPrice.IsZero() {
+			continue
+		}
+		price, ok := bot.market.Convert(usdPrice, alert.Currency)
+		if !ok {
+			continue
+		}
+		hit, change, ok := bot.priceAlertHit(alert, usdPrice, price, now)
+		if !ok {
+			continue
+		}
+		if !alert.Armed {
+			if priceAlertRearmed(alert, hit, now) {
+				alert.Armed = true
+				err = bot.dao.UpdatePriceAlert(alert)
+				if err != nil {
+					log.Error("Bot: checkPriceAlerts: dao.UpdatePriceAlert: %s", err.Error())
+				}
+			}
+			continue
+		}
+		if !hit {
+			continue
+		}
+		bot.mu.RLock()
+		user, found := bot.users[alert.UserID]
+		bot.mu.RUnlock()
+		if found && !user.Mute {
+			text := bot.priceAlertTriggeredText(alert, price, change, user.Lang)
+			err = bot.sendText(user, text)
+			if err != nil {
+				log.Error("Bot: checkPriceAlerts: sendText: %s", err.Error())
+			}
+		}
+		if alert.Recurring {
+			alert.Armed = false
+			alert.TriggeredAt = &now
+			err = bot.dao.UpdatePriceAlert(alert)
+			if err != nil {
+				log.Error("Bot: checkPriceAlerts: dao.UpdatePriceAlert: %s", err.Error())
+			}
+			continue
+		}
+		err = bot.dao.DeletePriceAlert(alert.UserID, alert.ID)
+		if err != nil {
+			log.Error("Bot: checkPriceAlerts: dao.DeletePriceAlert: %s", err.Error())
+		}
+	}
+}
+
+// priceAlertHit reports whether the alert condition holds, ok is false when there is not enough
+// price history to evaluate a change alert yet. The price is in the alert currency,
+// the change is computed from the USDT history.
+func (bot *Bot) priceAlertHit(alert models.PriceAlert, usdPrice decimal.Decimal, price decimal.Decimal, now time.Time) (hit bool, change decimal.Decimal, ok bool) {
+	switch alert.Kind {
+	case models.PriceAlertAbove:
+		return price.GreaterThanOrEqual(alert.Value), change, true
+	case models.PriceAlertBelow:
+		return price.LessThanOrEqual(alert.Value), change, true
+	case models.PriceAlertChange:
+		from := now.Add(-time.Duration(alert.Window) * time.Minute)
+		var reference decimal.Decimal
+		bot.mu.RLock()
+		for _, point := range bot.priceHistory[alert.Asset] {
+			if point.time.After(from) {
+				break
+			}
+			reference = point.price
+		}
+		bot.mu.RUnlock()
+		if reference.IsZero() {
+			return false, change, false
+		}
+		change = usdPrice.Sub(reference).Div(reference).Mul(hundred)
+		return change.Abs().GreaterThanOrEqual(alert.Value), change, true
+	}
+	return false, change, false
+}
+
+func priceAlertRearmed(alert models.PriceAlert, hit bool, now time.Time) bool {
+	if alert.Kind != models.PriceAlertChange {
+		return !hit
+	}
+	if alert.TriggeredAt == nil {
+		return true
+	}
+	return now.Sub(*alert.TriggeredAt) >= time.Duration(alert.Window)*time.Minute
+}
+
+// parsePriceAlert parses `<asset> above|below <price> [repeat]` or `<asset> change <percent> <minutes> [repeat]`.
+func parsePriceAlert(text string) (alert models.PriceAlert, ok bool) {
+	parts := strings.Fields(strings.ToLower(text))
+	if len(parts) > 0 && parts[len(parts)-1] == priceAlertRepeat {
+		alert.Recurring = true
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) < 3 {
+		return alert, false
+	}
+	alert.Asset = strings.ToUpper(parts[0])
+	if alert.Asset != market.AssetNAS && alert.Asset != market.AssetNAX {
+		return alert, false
+	}
+	alert.Kind = parts[1]
+	value, err := decimal.NewFromString(strings.TrimSuffix(parts[2], "%"))
+	if err != nil || !value.IsPositive() {
+		return alert, false
+	}
+	alert.Value = value
+	alert.Armed = true
+	switch alert.Kind {
+	case models.PriceAlertAbove, models.PriceAlertBelow:
+		return alert, len(parts) == 3
+	case models.PriceAlertChange:
+		if len(parts) != 4 {
+			return alert, false
+		}
+		window, err := strconv.ParseUint(parts[3], 10, 64)
+		if err != nil || window < minPriceAlertWindow || window > maxPriceAlertWindow {
+			return alert, false
+		}
+		alert.Window = window
+		return alert, true
+	}
+	return alert, false
+}
+
+func (bot *Bot) priceAlertText(alert models.PriceAlert, lang string) string {
+	var text string
+	switch alert.Kind {
+	case models.PriceAlertChange:
+		text = fmt.Sprintf(bot.dictionary.Get("t.price_alert_change", lang), alert.Asset, alert.Value.String(), alert.Window)
+	default:
+		text = fmt.Sprintf(
+			bot.dictionary.Get("t.price_alert_"+alert.Kind, lang),
+			alert.Asset,
+			formatCurrency(alert.Value, alert.Currency, pricePlaces),
+		)
+	}
+	if alert.Recurring {
+		text += bot.dictionary.Get("t.price_alert_recurring", lang)
+	}
+	return text
+}
+
+func (bot *Bot) priceAlertTriggeredText(alert models.PriceAlert, price decimal.Decimal, change decimal.Decimal, lang string) string {
+	if alert.Kind == models.PriceAlertChange {
+		return fmt.Sprintf(
+			bot.dictionary.Get("t.price_alert_triggered_change", lang),
+			alert.Asset,
+			change.StringFixed(2),
+			alert.Window,
+			formatCurrency(price, alert.Currency, pricePlaces),
+		)
+	}
+	return fmt.Sprintf(
+		bot.dictionary.Get("t.price_alert_triggered_"+alert.Kind, lang),
+		alert.Asset,
+		formatCurrency(price, alert.Currency, pricePlaces),
+		formatCurrency(alert.Value, alert.Currency, pricePlaces),
+	)
+}
+
+// sendPriceAlerts shows the alerts of the user with delete buttons, the message is edited when messageID is set.
+func (bot *Bot) sendPriceAlerts(user models.User, messageID int) error {
+	alerts, err := bot.dao.GetPriceAlerts(filters.PriceAlerts{UserIDs: []uint64{user.ID}})
+	if err != nil {
+		return fmt.Errorf("dao.GetPriceAlerts: %s", err.Error())
+	}
+	text := bot.dictionary.Get("t.no_price_alerts", user.Lang)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(alerts) != 0 {
+		lines := make([]string, len(alerts))
+		for i, alert := range alerts {
+			lines[i] = fmt.Sprintf("%d. %s", i+1, bot.priceAlertText(alert, user.Lang))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf(bot.dictionary.Get("b.delete_price_alert", user.Lang), i+1),
+				fmt.Sprintf("palert_%d", alert.ID),
+			)))
+		}
+		text = fmt.Sprintf(bot.dictionary.Get("t.price_alerts", user.Lang), strings.Join(lines, "\n"))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.add_price_alert", user.Lang), "palertadd"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageText(user.TgID, messageID, text)
+		edit.ReplyMarkup = &keyboard
+		return bot.sendMsg(edit)
+	}
+	msg := tgbotapi.NewMessage(user.TgID, text)
+	msg.ReplyMarkup = keyboard
+	return bot.sendMsg(msg)
+}
+
+func (bot *Bot) addPriceAlert(user models.User, alert models.PriceAlert) error {
+	alerts, err := bot.dao.GetPriceAlerts(filters.PriceAlerts{UserIDs: []uint64{user.ID}})
+	if err != nil {
+		return fmt.Errorf("dao.GetPriceAlerts: %s", err.Error())
+	}
+	if len(alerts) >= maxPriceAlerts {
+		return bot.sendText(user, fmt.Sprintf(bot.dictionary.Get("t.price_alerts_limit", user.Lang), maxPriceAlerts))
+	}
+	alert.UserID = user.ID
+	alert.Currency = user.Currency
+	_, err = bot.dao.CreatePriceAlert(alert)
+	if err != nil {
+		return fmt.Errorf("dao.CreatePriceAlert: %s", err.Error())
+	}
+	return bot.sendPriceAlerts(user, 0)
+}
@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+func TestParsePriceAlert(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want models.PriceAlert
+		ok   bool
+	}{
+		{
+			name: "above",
+			text: "nas above 0.5",
+			want: models.PriceAlert{Asset: "NAS", Kind: models.PriceAlertAbove, Value: decimal.New(5, -1), Armed: true},
+			ok:   true,
+		},
+		{
+			name: "below repeat",
+			text: "NAX Below 0.002 repeat",
+			want: models.PriceAlert{Asset: "NAX", Kind: models.PriceAlertBelow, Value: decimal.New(2, -3), Armed: true, Recurring: true},
+			ok:   true,
+		},
+		{
+			name: "change",
+			text: "nas change 10% 60",
+			want: models.PriceAlert{Asset: "NAS", Kind: models.PriceAlertChange, Value: decimal.New(10, 0), Window: 60, Armed: true},
+			ok:   true,
+		},
+		{name: "unknown asset", text: "btc above 1", ok: false},
+		{name: "unknown kind", text: "nas equal 1", ok: false},
+		{name: "zero price", text: "nas above 0", ok: false},
+		{name: "negative price", text: "nas below -1", ok: false},
+		{name: "not a number", text: "nas above one", ok: false},
+		{name: "extra argument", text: "nas above 1 60", ok: false},
+		{name: "change without window", text: "nas change 10", ok: false},
+		{name: "short window", text: "nas change 10 1", ok: false},
+		{name: "long window", text: "nas change 10 10000", ok: false},
+		{name: "too short", text: "nas above", ok: false},
+		{name: "repeat only", text: "repeat", ok: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parsePriceAlert(test.text)
+			if ok != test.ok {
+				t.Fatalf("parsePriceAlert(%q) ok = %t, want %t", test.text, ok, test.ok)
+			}
+			if !ok {
+				return
+			}
+			if got.Asset != test.want.Asset || got.Kind != test.want.Kind || !got.Value.Equal(test.want.Value) ||
+				got.Window != test.want.Window || got.Recurring != test.want.Recurring || got.Armed != test.want.Armed {
+				t.Errorf("parsePriceAlert(%q) = %+v, want %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
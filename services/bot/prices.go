@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/market"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+const sparklinePoints = 24
+
+var (
+	priceChangePeriods = []struct {
+		title  string
+		period time.Duration
+	}{
+		{title: "1h", period: time.Hour},
+		{title: "24h", period: time.Hour * 24},
+		{title: "7d", period: time.Hour * 24 * 7},
+	}
+	sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+)
+
+// storePrices is called on every market tick and stores the refreshed prices.
+func (bot *Bot) storePrices() {
+	var items []models.Price
+	bot.mu.Lock()
+	for _, asset := range []string{market.AssetNAS, market.AssetNAX} {
+		updated := bot.market.LastUpdated(asset)
+		if updated.IsZero() || !updated.After(bot.pricesStoredAt[asset]) {
+			continue
+		}
+		bot.pricesStoredAt[asset] = updated
+		items = append(items, models.Price{
+			Asset:     asset,
+			Value:     bot.market.GetPrice(asset),
+			CreatedAt: updated,
+		})
+	}
+	bot.mu.Unlock()
+	err := bot.dao.CreatePrices(items)
+	if err != nil {
+		log.Error("Bot: storePrices: dao.CreatePrices: %s", err.Error())
+	}
+}
+
+// setPriceHistory restores the history of the change price alerts after restart.
+func (bot *Bot) setPriceHistory() error {
+	from := time.Now().Add(-time.Duration(maxPriceAlertWindow+minPriceAlertWindow) * time.Minute)
+	prices, err := bot.dao.GetPrices(filters.Prices{From: from})
+	if err != nil {
+		return fmt.Errorf("dao.GetPrices: %s", err.Error())
+	}
+	bot.mu.Lock()
+	for _, price := range prices {
+		bot.priceHistory[price.Asset] = append(bot.priceHistory[price.Asset], pricePoint{
+			price: price.Value,
+			time:  price.CreatedAt,
+		})
+		bot.pricesStoredAt[price.Asset] = price.CreatedAt
+	}
+	bot.mu.Unlock()
+	return nil
+}
+
+// sendPrices shows the current prices with the changes and the last 24 hours chart.
+func (bot *Bot) sendPrices(user models.User) error {
+	now := time.Now()
+	longest := priceChangePeriods[len(priceChangePeriods)-1].period
+	prices, err := bot.dao.GetPrices(filters.Prices{From: now.Add(-longest - time.Hour)})
+	if err != nil {
+		return fmt.Errorf("dao.GetPrices: %s", err.Error())
+	}
+	history := make(map[string][]models.Price) // [asset]
+	for _, price := range prices {
+		history[price.Asset] = append(history[price.Asset], price)
+	}
+	var cards []string
+	for _, asset := range []string{market.AssetNAS, market.AssetNAX} {
+		current := bot.market.GetPrice(asset)
+		if current.IsZero() {
+			if len(history[asset]) == 0 {
+				continue
+			}
+			current = history[asset][len(history[asset])-1].Value
+		}
+		changes := make([]string, len(priceChangePeriods))
+		for i, p := range priceChangePeriods {
+			changes[i] = fmt.Sprintf("%s: %s", p.title, bot.priceChangeText(history[asset], current, now.Add(-p.period), user.Lang))
+		}
+		cards = append(cards, fmt.Sprintf(
+			bot.dictionary.Get("t.price_card", user.Lang),
+			asset,
+			bot.fiatValue(current, user.Currency, pricePlaces),
+			strings.Join(changes, " | "),
+			sparkline(history[asset], now.Add(-time.Hour*24), now),
+		))
+	}
+	if len(cards) == 0 {
+		return bot.sendText(user, bot.dictionary.Get("t.no_prices", user.Lang))
+	}
+	return bot.sendText(user, strings.Join(cards, "\n\n")+"\n"+bot.stalePricesText(user.Lang))
+}
+
+func (bot *Bot) priceChangeText(history []models.Price, current decimal.Decimal, at time.Time, lang string) string {
+	var reference decimal.Decimal
+	for _, price := range history {
+		if price.CreatedAt.After(at) {
+			break
+		}
+		reference = price.Value
+	}
+	if reference.IsZero() {
+		return bot.dictionary.Get("t.price_change_na", lang)
+	}
+	change := current.Sub(reference).Div(reference).Mul(hundred).StringFixed(2) + "%"
+	if current.GreaterThan(reference) {
+		change = "+" + change
+	}
+	return change
+}
+
+// sparkline draws the prices of the period as sparklinePoints bars, an empty bucket repeats the previous bar.
+func sparkline(history []models.Price, from time.Time, to time.Time) string {
+	step := to.Sub(from) / sparklinePoints
+	points := make([]decimal.Decimal, sparklinePoints)
+	for _, price := range history {
+		if price.CreatedAt.Before(from) || !price.CreatedAt.Before(to) {
+			continue
+		}
+		points[int(price.CreatedAt.Sub(from)/step)] = price.Value
+	}
+	var values []decimal.Decimal
+	for i, point := range points {
+		if point.IsZero() && i > 0 {
+			points[i] = points[i-1]
+		}
+		if !points[i].IsZero() {
+			values = append(values, points[i])
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	min, max := values[0], values[0]
+	for _, value := range values {
+		min = decimal.Min(min, value)
+		max = decimal.Max(max, value)
+	}
+	levels := decimal.New(int64(len(sparklineLevels)-1), 0)
+	var b strings.Builder
+	for _, value := range values {
+		level := 0
+		if max.GreaterThan(min) {
+			level = int(value.Sub(min).Div(max.Sub(min)).Mul(levels).Round(0).IntPart())
+		}
+		b.WriteRune(sparklineLevels[level])
+	}
+	return b.String()
+}
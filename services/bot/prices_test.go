@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/shopspring/decimal"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	to := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-sparklinePoints * time.Hour)
+	// price returns the price stored at the start of the hour bucket
+	price := func(hour int, value int64) models.Price {
+		return models.Price{Value: decimal.New(value, 0), CreatedAt: from.Add(time.Duration(hour) * time.Hour)}
+	}
+	rising := make([]models.Price, sparklinePoints)
+	for i := range rising {
+		rising[i] = price(i, int64(i*7+1))
+	}
+	tests := []struct {
+		name    string
+		history []models.Price
+		want    string
+	}{
+		{name: "empty", history: nil, want: ""},
+		{name: "out of the period", history: []models.Price{price(-1, 1), price(sparklinePoints, 1)}, want: ""},
+		{name: "flat", history: []models.Price{price(0, 5)}, want: "▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁▁"},
+		{name: "rising", history: rising, want: "▁▁▂▂▂▃▃▃▃▄▄▄▅▅▅▆▆▆▆▇▇▇██"},
+		{name: "gaps repeat the previous bar", history: []models.Price{price(0, 1), price(12, 2)}, want: "▁▁▁▁▁▁▁▁▁▁▁▁████████████"},
+		{name: "leading gap is skipped", history: []models.Price{price(20, 1), price(22, 3)}, want: "▁▁██"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := sparkline(test.history, from, to)
+			if got != test.want {
+				t.Errorf("sparkline = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// missedSlotsAlert is the number of consecutive missed slots after which the node watchers are alerted.
+const missedSlotsAlert = 3
+
+type (
+	// productionState tracks block production of the current dynasty, guarded by bot.mu.
+	productionState struct {
+		dynastyRoot   string
+		miners        []string
+		lastTimestamp int64
+		stats         map[string]minerStats // [miner]
+	}
+	minerStats struct {
+		Produced          int
+		Expected          int
+		ConsecutiveMissed int
+		LastBlock         uint64
+	}
+)
+
+// trackProduction accounts the block and the slots missed since the previous block.
+func (bot *Bot) trackProduction(block node.Block) {
+	b := block.Result
+	bot.mu.RLock()
+	dynastyRoot := bot.production.dynastyRoot
+	lastTimestamp := bot.production.lastTimestamp
+	bot.mu.RUnlock()
+	if b.Timestamp <= lastTimestamp {
+		return // the block is parsed again after an error
+	}
+	// a failed or empty dynasty is cached too, the missed slots are not counted until the next dynasty
+	var miners []string
+	newDynasty := b.ConsensusRoot.DynastyRoot != dynastyRoot
+	if newDynasty {
+		dynasty, err := bot.node.GetDynasty(b.Height)
+		if err != nil {
+			log.Error("Bot: trackProduction: node.GetDynasty(%d): %s", b.Height, err.Error())
+		} else {
+			miners = dynasty.Result.Miners
+			if len(miners) == 0 {
+				log.Warn("Bot: trackProduction: node.GetDynasty(%d): empty miners list", b.Height)
+			}
+		}
+	}
+
+	var messages []tgbotapi.MessageConfig
+	bot.mu.Lock()
+	p := &bot.production
+	if newDynasty {
+		// consecutive misses survive the dynasty change, a stuck node stays stuck
+		stats := make(map[string]minerStats, len(miners))
+		for _, miner := range miners {
+			stats[miner] = minerStats{ConsecutiveMissed: p.stats[miner].ConsecutiveMissed}
+		}
+		p.dynastyRoot = b.ConsensusRoot.DynastyRoot
+		p.miners = miners
+		p.stats = stats
+	}
+	if p.stats == nil {
+		p.stats = make(map[string]minerStats)
+	}
+	if p.lastTimestamp != 0 {
+		for ts := p.lastTimestamp + node.BlockInterval; ts < b.Timestamp; ts += node.BlockInterval {
+			miner := node.ProposerAt(ts, p.miners)
+			if miner == "" {
+				continue
+			}
+			s := p.stats[miner]
+			s.Expected++
+			s.ConsecutiveMissed++
+			p.stats[miner] = s
+			if s.ConsecutiveMissed == missedSlotsAlert {
+				messages = append(messages, bot.productionMessages(miner, "t.missed_slots", s)...)
+			}
+		}
+	}
+	s := p.stats[b.Miner]
+	if s.ConsecutiveMissed >= missedSlotsAlert {
+		messages = append(messages, bot.productionMessages(b.Miner, "t.production_resumed", s)...)
+	}
+	s.Expected++
+	s.Produced++
+	s.ConsecutiveMissed = 0
+	s.LastBlock = b.Height
+	p.stats[b.Miner] = s
+	p.lastTimestamp = b.Timestamp
+	bot.mu.Unlock()
+
+	for _, msg := range messages {
+		err := bot.sendMsg(msg)
+		if err != nil {
+			log.Error("Bot: trackProduction: api.Send: %s", err.Error())
+		}
+	}
+}
+
+// productionMessages builds messages for the watchers of the miner node, bot.mu must be held.
+func (bot *Bot) productionMessages(miner string, key string, s minerStats) (messages []tgbotapi.MessageConfig) {
+	nodeID, ok := bot.nodeAccounts[miner]
+	if !ok {
+		return nil
+	}
+	n := bot.nodes[nodeID]
+	for _, user := range bot.nodeUsers(n) {
+		if user.Mute {
+			continue
+		}
+		text := fmt.Sprintf(bot.dictionary.Get(key, user.Lang), nodeTitle(n), s.ConsecutiveMissed, s.LastBlock)
+		messages = append(messages, tgbotapi.NewMessage(user.TgID, text))
+	}
+	return messages
+}
+
+func (bot *Bot) sendProduction(user models.User, query string) error {
+	list := bot.findNodes(query)
+	if len(list) == 0 {
+		return bot.sendText(user, bot.dictionary.Get("t.validators_not_found", user.Lang))
+	}
+	n := list[0]
+	for _, item := range list {
+		if item.ID == query {
+			n = item
+		}
+	}
+	bot.mu.RLock()
+	var (
+		miner string
+		s     minerStats
+		ok    bool
+	)
+	for _, address := range nodeAddresses(n) {
+		s, ok = bot.production.stats[address]
+		if ok {
+			miner = address
+			break
+		}
+	}
+	dynastyRoot := bot.production.dynastyRoot
+	minersCount := len(bot.production.miners)
+	bot.mu.RUnlock()
+	if !ok {
+		return bot.sendText(user, fmt.Sprintf(bot.dictionary.Get("t.production_not_in_dynasty", user.Lang), nodeTitle(n)))
+	}
+	return bot.sendText(user, fmt.Sprintf(
+		bot.dictionary.Get("t.production", user.Lang),
+		nodeTitle(n),
+		miner,
+		dynastyRoot,
+		minersCount,
+		s.Produced,
+		s.Expected,
+		s.Expected-s.Produced,
+		s.ConsecutiveMissed,
+		s.LastBlock,
+	))
+}
@@ -0,0 +1,277 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+const rewardsDigestPeriod = time.Hour * 24
+
+type (
+	rewardTransfer struct {
+		to    string
+		value decimal.Decimal // in NAX
+	}
+	// naxTransferEvent is the NRC20 event emitted by the NAX contract for every transfer.
+	naxTransferEvent struct {
+		Transfer *struct {
+			From  string          `json:"from"`
+			To    string          `json:"to"`
+			Value decimal.Decimal `json:"value"`
+		} `json:"Transfer"`
+	}
+)
+
+type rewardsSummary struct {
+	total map[string]decimal.Decimal // [address]
+	day   map[string]decimal.Decimal // [address]
+	nodes map[string]decimal.Decimal // [nodeID]
+}
+
+func (bot *Bot) isRewardSource(address string) bool {
+	for _, source := range bot.cfg.Rewards.Sources {
+		if source == address {
+			return true
+		}
+	}
+	return false
+}
+
+// addressUsers returns users subscribed to the address, bot.mu must be held.
+func (bot *Bot) addressUsers(address string) map[uint64]models.User {
+	users := make(map[uint64]models.User)
+	for userID := range bot.addresses[address] {
+		user, ok := bot.users[userID]
+		if ok {
+			users[userID] = user
+		}
+	}
+	return users
+}
+
+// rewardCall returns the NAX transfer sent by one of the reward sources, ok is false when the transaction is not a reward.
+func (bot *Bot) rewardCall(tx node.Transaction) (call node.StakingCall, ok bool) {
+	if tx.Status != 1 || tx.To != node.NAXContract || !bot.isRewardSource(tx.From) {
+		return call, false
+	}
+	call, err := node.DecodeStakingCall(tx.Data)
+	if err != nil || call.Kind != node.StakingTransfer || call.Address == "" {
+		return call, false
+	}
+	return call, true
+}
+
+// distributionTransfers returns the NAX transfers paid by the staking contract or by one of the reward sources
+// during the transaction.
+func (bot *Bot) distributionTransfers(events []node.Event) (transfers []rewardTransfer) {
+	for _, event := range events {
+		var data naxTransferEvent
+		err := json.Unmarshal([]byte(event.Data), &data)
+		if err != nil || data.Transfer == nil || data.Transfer.To == "" || !data.Transfer.Value.IsPositive() {
+			continue
+		}
+		if data.Transfer.From != StakingContract && !bot.isRewardSource(data.Transfer.From) {
+			continue
+		}
+		transfers = append(transfers, rewardTransfer{
+			to:    data.Transfer.To,
+			value: data.Transfer.Value.Div(node.PrecisionDivNAX),
+		})
+	}
+	return transfers
+}
+
+// rewardNotify records and announces the rewards paid by the transaction, returns false when it pays no rewards.
+// A reward is a NAX transfer called by one of the reward sources, or a NAX transfer from the staking contract
+// made by a staking contract call the bot does not decode (the distribution).
+func (bot *Bot) rewardNotify(h uint64, tx node.Transaction) bool {
+	call, ok := bot.rewardCall(tx)
+	if ok {
+		bot.recordReward(h, tx, call.Address, call.Amount())
+		return true
+	}
+	if tx.Status != 1 || tx.To != StakingContract {
+		return false
+	}
+	call, err := node.DecodeStakingCall(tx.Data)
+	if err != nil || call.Kind != node.StakingUnknown {
+		return false
+	}
+	events, err := bot.node.GetEventsByHash(tx.Hash)
+	if err != nil {
+		log.Error("Bot: rewardNotify: node.GetEventsByHash(%s): %s", tx.Hash, err.Error())
+		return false
+	}
+	transfers := bot.distributionTransfers(events)
+	for _, transfer := range transfers {
+		bot.recordReward(h, tx, transfer.to, transfer.value)
+	}
+	return len(transfers) != 0
+}
+
+// recordReward stores and announces the reward of the subscribed account or validator, value is in NAX.
+func (bot *Bot) recordReward(h uint64, tx node.Transaction, to string, value decimal.Decimal) {
+	bot.mu.RLock()
+	nodeID := bot.nodeAccounts[to]
+	users := bot.addressUsers(to)
+	bot.mu.RUnlock()
+	// only the rewards of the subscribed accounts and of the validators are recorded
+	if len(users) == 0 && nodeID == "" {
+		return
+	}
+
+	err := bot.dao.CreateReward(models.Reward{
+		Address: to,
+		NodeID:  nodeID,
+		Value:   value,
+		TxHash:  tx.Hash,
+		Block:   h,
+	})
+	if err != nil {
+		if err.Error() == derrors.ErrDuplicate {
+			return // the block is parsed again after an error
+		}
+		log.Error("Bot: recordReward: dao.CreateReward: %s", err.Error())
+		return
+	}
+	total := value
+	totals, err := bot.dao.GetAddressesRewards(filters.Rewards{Addresses: []string{to}})
+	if err != nil {
+		log.Error("Bot: recordReward: dao.GetAddressesRewards: %s", err.Error())
+	} else if len(totals) != 0 {
+		total = totals[0].Value
+	}
+
+	bot.sendWebhooks([]string{to}, webhook.Event{
+		Event: webhook.EventReward,
+		Data: webhook.RewardData{
+			NodeID:      nodeID,
+			Value:       value,
+			Transaction: tx,
+		},
+	})
+	for _, user := range users {
+		if user.Mute {
+			continue
+		}
+		text := fmt.Sprintf(
+			bot.dictionary.Get("t.reward_received", user.Lang),
+			to,
+			value.Truncate(4).String(),
+			total.Truncate(4).String(),
+		)
+		err = bot.sendMsg(tgbotapi.NewMessage(user.TgID, text))
+		if err != nil {
+			log.Error("Bot: recordReward: api.Send: %s", err.Error())
+		}
+	}
+}
+
+// sendRewardsDigest sends every user the rewards received by the subscribed addresses during the last day.
+func (bot *Bot) sendRewardsDigest() {
+	totals, err := bot.dao.GetAddressesRewards(filters.Rewards{From: time.Now().Add(-rewardsDigestPeriod)})
+	if err != nil {
+		log.Error("Bot: sendRewardsDigest: dao.GetAddressesRewards: %s", err.Error())
+		return
+	}
+	naxPrice := bot.market.GetNAXPrice()
+	users := make(map[uint64]models.User)
+	lines := make(map[uint64][]string) // [userID]
+	bot.mu.RLock()
+	for _, total := range totals {
+		for _, user := range bot.addressUsers(total.Key) {
+			if user.Mute {
+				continue
+			}
+			users[user.ID] = user
+			lines[user.ID] = append(lines[user.ID], fmt.Sprintf(
+				bot.dictionary.Get("t.rewards_digest_item", user.Lang),
+				total.Key,
+				total.Value.Truncate(4).String(),
+				bot.fiatValue(total.Value.Mul(naxPrice), user.Currency, 4),
+			))
+		}
+	}
+	bot.mu.RUnlock()
+	for userID, items := range lines {
+		user := users[userID]
+		text := bot.dictionary.Get("t.rewards_digest", user.Lang) + "\n" + strings.Join(items, "\n")
+		err = bot.sendMsg(tgbotapi.NewMessage(user.TgID, text))
+		if err != nil {
+			log.Error("Bot: sendRewardsDigest: api.Send: %s", err.Error())
+		}
+	}
+}
+
+func (bot *Bot) getRewardsSummary(states []models.AddressState) (summary rewardsSummary, err error) {
+	summary = rewardsSummary{
+		total: make(map[string]decimal.Decimal),
+		day:   make(map[string]decimal.Decimal),
+		nodes: make(map[string]decimal.Decimal),
+	}
+	var addresses, nodeIDs []string
+	for _, state := range states {
+		addresses = append(addresses, state.Address)
+		if state.NodeID != "" {
+			nodeIDs = append(nodeIDs, state.NodeID)
+		}
+	}
+	if len(addresses) == 0 {
+		return summary, nil
+	}
+	totals, err := bot.dao.GetAddressesRewards(filters.Rewards{Addresses: addresses})
+	if err != nil {
+		return summary, fmt.Errorf("dao.GetAddressesRewards: %s", err.Error())
+	}
+	for _, t := range totals {
+		summary.total[t.Key] = t.Value
+	}
+	totals, err = bot.dao.GetAddressesRewards(filters.Rewards{
+		Addresses: addresses,
+		From:      time.Now().Add(-rewardsDigestPeriod),
+	})
+	if err != nil {
+		return summary, fmt.Errorf("dao.GetAddressesRewards: %s", err.Error())
+	}
+	for _, t := range totals {
+		summary.day[t.Key] = t.Value
+	}
+	if len(nodeIDs) != 0 {
+		totals, err = bot.dao.GetNodesRewards(filters.Rewards{NodeIDs: nodeIDs})
+		if err != nil {
+			return summary, fmt.Errorf("dao.GetNodesRewards: %s", err.Error())
+		}
+		for _, t := range totals {
+			summary.nodes[t.Key] = t.Value
+		}
+	}
+	return summary, nil
+}
+
+// rewardsText is appended to the subscription card, empty when nothing was received.
+func (bot *Bot) rewardsText(summary rewardsSummary, state models.AddressState, lang string) string {
+	var text string
+	total, ok := summary.total[state.Address]
+	if ok {
+		text += fmt.Sprintf(
+			bot.dictionary.Get("t.rewards_line", lang),
+			total.Truncate(4).String(),
+			summary.day[state.Address].Truncate(4).String(),
+		)
+	}
+	nodeTotal, ok := summary.nodes[state.NodeID]
+	if ok {
+		text += fmt.Sprintf(bot.dictionary.Get("t.node_rewards_line", lang), nodeTotal.Truncate(4).String())
+	}
+	return text
+}
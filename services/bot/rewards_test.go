@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/everstake/nebulas-tg-bot/config"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+const (
+	testSource  = "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"
+	testStaker  = "n1Z6SbjLuAEXfhX1UJvXT6BB5osWYxVg3F3"
+	testStaker2 = "n1H2Yb5Q6X3rVzPCH5PRAjCqQtSJkq5Kf4R"
+)
+
+func testCallData(function string, args ...interface{}) string {
+	encoded, _ := json.Marshal(args)
+	data, _ := json.Marshal(node.CallContract{Function: function, Args: string(encoded)})
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func transferEventData(from string, to string, value string) string {
+	return `{"Status":true,"Transfer":{"from":"` + from + `","to":"` + to + `","value":"` + value + `"}}`
+}
+
+func TestDistributionTransfers(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Rewards.Sources = []string{testSource}
+	bot, _ := newTestBot(cfg)
+	events := []node.Event{
+		{Topic: "chain.contract.NAX", Data: transferEventData(StakingContract, testStaker, "2000000000")},
+		{Topic: "chain.contract.NAX", Data: transferEventData(testSource, testStaker2, "1000000000")},
+		{Topic: "chain.contract.NAX", Data: transferEventData(testStaker, testStaker2, "5")},     // not a distribution
+		{Topic: "chain.contract.NAX", Data: transferEventData(StakingContract, testStaker, "0")}, // nothing paid
+		{Topic: "chain.contract.NAX", Data: `{"Status":true}`},
+		{Topic: node.TopicTransactionResult, Data: `{"hash":"h","status":1}`},
+		{Topic: "chain.contract.NAX", Data: `not json`},
+	}
+	transfers := bot.distributionTransfers(events)
+	want := []rewardTransfer{
+		{to: testStaker, value: decimal.New(2, 0)},
+		{to: testStaker2, value: decimal.New(1, 0)},
+	}
+	if len(transfers) != len(want) {
+		t.Fatalf("distributionTransfers = %+v, want %+v", transfers, want)
+	}
+	for i := range want {
+		if transfers[i].to != want[i].to || !transfers[i].value.Equal(want[i].value) {
+			t.Errorf("distributionTransfers[%d] = %+v, want %+v", i, transfers[i], want[i])
+		}
+	}
+}
+
+func TestRewardNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []string
+		tx      node.Transaction
+		events  []node.Event
+		reward  bool
+		want    []string // recorded rewards: address=value
+	}{
+		{
+			name: "distribution without the sources",
+			tx:   node.Transaction{Hash: "h1", Status: 1, To: StakingContract, Type: node.TxTypeCall, Data: testCallData("distribute")},
+			events: []node.Event{
+				{Topic: "chain.contract.NAX", Data: transferEventData(StakingContract, testStaker, "3000000000")},
+				{Topic: "chain.contract.NAX", Data: transferEventData(StakingContract, testStaker2, "1000000000")},
+			},
+			reward: true,
+			want:   []string{testStaker + "=3"},
+		},
+		{
+			name:    "transfer of a source",
+			sources: []string{testSource},
+			tx: node.Transaction{Hash: "h2", Status: 1, From: testSource, To: node.NAXContract, Type: node.TxTypeCall,
+				Data: testCallData("transfer", testStaker, "500000000")},
+			reward: true,
+			want:   []string{testStaker + "=0.5"},
+		},
+		{
+			name: "vote",
+			tx:   node.Transaction{Hash: "h3", Status: 1, To: StakingContract, Type: node.TxTypeCall, Data: testCallData("vote", "node", "1")},
+			events: []node.Event{
+				{Topic: "chain.contract.NAX", Data: transferEventData(StakingContract, testStaker, "1")},
+			},
+		},
+		{
+			name: "failed distribution",
+			tx:   node.Transaction{Hash: "h4", Status: 0, To: StakingContract, Type: node.TxTypeCall, Data: testCallData("distribute")},
+			events: []node.Event{
+				{Topic: "chain.contract.NAX", Data: transferEventData(StakingContract, testStaker, "1")},
+			},
+		},
+		{
+			name: "unknown call without transfers",
+			tx:   node.Transaction{Hash: "h5", Status: 1, To: StakingContract, Type: node.TxTypeCall, Data: testCallData("claim")},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Rewards.Sources = test.sources
+			bot, d := newTestBot(cfg)
+			bot.node = &testNode{events: map[string][]node.Event{test.tx.Hash: test.events}}
+			// the muted subscriber gets the reward recorded without a message
+			bot.users[1] = models.User{ID: 1, Mute: true}
+			bot.addresses[testStaker] = map[uint64]struct{}{1: {}}
+
+			reward := bot.rewardNotify(100, test.tx)
+			if reward != test.reward {
+				t.Fatalf("rewardNotify = %t, want %t", reward, test.reward)
+			}
+			var got []string
+			for _, r := range d.rewards {
+				got = append(got, r.Address+"="+r.Value.String())
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("recorded %q, want %q", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("recorded %q, want %q", got, test.want)
+				}
+			}
+		})
+	}
+}
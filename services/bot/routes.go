@@ -2,21 +2,32 @@ package bot
 
 import (
 	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
 	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
 	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/market"
+	"github.com/everstake/nebulas-tg-bot/services/node"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/shopspring/decimal"
 	"strings"
 )
 
 const (
-	RouteStart          = "start"
-	RouteChooseLang     = "choose_lang"
-	RouteSettings       = "settings"
-	RouteTypeAddress    = "type_address"
-	RoutePasteAddress   = "paste_address"
-	RouteAddressAlias   = "address_alias"
-	RouteChangeThreshold = "change_threshold"
+	RouteStart            = "start"
+	RouteChooseLang       = "choose_lang"
+	RouteSettings         = "settings"
+	RouteTypeAddress      = "type_address"
+	RoutePasteAddress     = "paste_address"
+	RouteAddressAlias     = "address_alias"
+	RouteChangeThreshold  = "change_threshold"
+	RouteWebhook          = "webhook"
+	RouteSearchValidator  = "search_validator"
+	RouteChangeWhaleShare = "change_whale_share"
+	RouteChangeStability  = "change_stability"
+	RouteAddPriceAlert    = "add_price_alert"
+	RouteChangeCurrency   = "change_currency"
+	RouteContractFilter   = "contract_filter"
 )
 
 type Route struct {
@@ -73,6 +84,9 @@ func (bot *Bot) SetRoutes() {
 					tgbotapi.NewKeyboardButtonRow(
 						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.add_subscription", user.Lang)),
 					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.follow_validator", user.Lang)),
+					),
 					tgbotapi.NewKeyboardButtonRow(
 						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.show_subscriptions", user.Lang)),
 					),
@@ -96,7 +110,12 @@ func (bot *Bot) SetRoutes() {
 						return fmt.Errorf("openRoute: %s", err.Error())
 					}
 				case bot.dictionary.Get("b.add_subscription", user.Lang):
-					err := bot.openRoute(RouteTypeAddress, user)
+					err := bot.openRoute(RoutePasteAddress, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+				case bot.dictionary.Get("b.follow_validator", user.Lang):
+					err := bot.openRoute(RouteSearchValidator, user)
 					if err != nil {
 						return fmt.Errorf("openRoute: %s", err.Error())
 					}
@@ -130,6 +149,15 @@ func (bot *Bot) SetRoutes() {
 					tgbotapi.NewKeyboardButtonRow(
 						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.change_threshold", user.Lang)),
 					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.whale_share", user.Lang)),
+					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.stability_alerts", user.Lang)),
+					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.currency", user.Lang)),
+					),
 					tgbotapi.NewKeyboardButtonRow(
 						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.return_back", user.Lang)),
 					),
@@ -182,6 +210,21 @@ func (bot *Bot) SetRoutes() {
 					if err != nil {
 						return fmt.Errorf("openRoute: %s", err.Error())
 					}
+				case bot.dictionary.Get("b.whale_share", user.Lang):
+					err := bot.openRoute(RouteChangeWhaleShare, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+				case bot.dictionary.Get("b.stability_alerts", user.Lang):
+					err := bot.openRoute(RouteChangeStability, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+				case bot.dictionary.Get("b.currency", user.Lang):
+					err := bot.openRoute(RouteChangeCurrency, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
 				default:
 					msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.wrong_option", user.Lang))
 					_, err := bot.api.Send(msg)
@@ -194,19 +237,41 @@ func (bot *Bot) SetRoutes() {
 		},
 		RouteTypeAddress: {
 			request: func(user models.User) error {
-				var keyboard = tgbotapi.NewReplyKeyboard(
+				var rows [][]tgbotapi.KeyboardButton
+				text := bot.dictionary.Get("t.choose_address_type", user.Lang)
+				item, ok := bot.GetCachedItem(user.ID, "address_info")
+				if ok {
+					info := item.(addressInfo)
+					text = fmt.Sprintf(
+						bot.dictionary.Get("t.confirm_address_type", user.Lang),
+						bot.addressInfoText(info, user.Lang),
+						bot.dictionary.Get("b."+info.Type+"_address", user.Lang),
+					)
+					rows = append(rows, tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.confirm", user.Lang)),
+					))
+					if info.NodeID != "" {
+						rows = append(rows, tgbotapi.NewKeyboardButtonRow(
+							tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.follow_node", user.Lang)),
+						))
+					}
+				}
+				rows = append(rows,
 					tgbotapi.NewKeyboardButtonRow(
 						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.account_address", user.Lang)),
 					),
 					tgbotapi.NewKeyboardButtonRow(
 						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.validator_address", user.Lang)),
 					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.contract_address", user.Lang)),
+					),
 					tgbotapi.NewKeyboardButtonRow(
 						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.cancel", user.Lang)),
 					),
 				)
-				msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.choose_address_type", user.Lang))
-				msg.ReplyMarkup = keyboard
+				msg := tgbotapi.NewMessage(user.TgID, text)
+				msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(rows...)
 				_, err := bot.api.Send(msg)
 				if err != nil {
 					return fmt.Errorf("api.Send: %s", err.Error())
@@ -223,10 +288,40 @@ func (bot *Bot) SetRoutes() {
 				}
 				var err error
 				switch update.Message.Text {
+				case bot.dictionary.Get("b.confirm", user.Lang):
+					_, ok := bot.GetCachedItem(user.ID, "type_address")
+					if !ok {
+						return bot.oops(user)
+					}
+				case bot.dictionary.Get("b.follow_node", user.Lang):
+					item, ok := bot.GetCachedItem(user.ID, "address_info")
+					if !ok || item.(addressInfo).NodeID == "" {
+						return bot.oops(user)
+					}
+					nodeID := item.(addressInfo).NodeID
+					text := fmt.Sprintf(bot.dictionary.Get("t.node_followed", user.Lang), nodeID)
+					err = bot.followNode(user, nodeID)
+					if err != nil {
+						if err.Error() != derrors.ErrDuplicate {
+							return fmt.Errorf("followNode: %s", err.Error())
+						}
+						text = bot.dictionary.Get("t.node_already_followed", user.Lang)
+					}
+					err = bot.sendText(user, text)
+					if err != nil {
+						return fmt.Errorf("sendText: %s", err.Error())
+					}
+					err = bot.openRoute(RouteStart, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
 				case bot.dictionary.Get("b.account_address", user.Lang):
 					bot.SetCachedItem(user.ID, "type_address", "account")
 				case bot.dictionary.Get("b.validator_address", user.Lang):
 					bot.SetCachedItem(user.ID, "type_address", "validator")
+				case bot.dictionary.Get("b.contract_address", user.Lang):
+					bot.SetCachedItem(user.ID, "type_address", "contract")
 				default:
 					msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.wrong_option", user.Lang))
 					_, err := bot.api.Send(msg)
@@ -235,7 +330,7 @@ func (bot *Bot) SetRoutes() {
 					}
 					return nil
 				}
-				err = bot.openRoute(RoutePasteAddress, user)
+				err = bot.openRoute(RouteAddressAlias, user)
 				if err != nil {
 					return fmt.Errorf("openRoute: %s", err.Error())
 				}
@@ -267,8 +362,9 @@ func (bot *Bot) SetRoutes() {
 					return nil
 				}
 				text = strings.TrimSpace(text)
-				if len(text) != 35 || text[0] != 'n' {
-					msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.wrong_address", user.Lang))
+				_, err := node.ParseAddress(text)
+				if err != nil {
+					msg := tgbotapi.NewMessage(user.TgID, bot.addressErrorText(err, user.Lang))
 					_, err := bot.api.Send(msg)
 					if err != nil {
 						return fmt.Errorf("api.Send: %s", err.Error())
@@ -302,8 +398,14 @@ func (bot *Bot) SetRoutes() {
 					}
 				}
 
+				info, err := bot.detectAddress(text)
+				if err != nil {
+					log.Warn("Bot: detectAddress(%s): %s", text, err.Error())
+				}
 				bot.SetCachedItem(user.ID, "address", text)
-				err = bot.openRoute(RouteAddressAlias, user)
+				bot.SetCachedItem(user.ID, "address_info", info)
+				bot.SetCachedItem(user.ID, "type_address", info.Type)
+				err = bot.openRoute(RouteTypeAddress, user)
 				if err != nil {
 					return fmt.Errorf("openRoute: %s", err.Error())
 				}
@@ -337,45 +439,20 @@ func (bot *Bot) SetRoutes() {
 				if !ok {
 					return bot.oops(user)
 				}
-				address := item.(string)
-				addresses, err := bot.dao.GetAddresses(filters.Addresses{Addresses: []string{address}})
-				if err != nil {
-					return fmt.Errorf("dao.GetAddresses: %s", err.Error())
-				}
-				var addressModel models.Address
-				if len(addresses) == 0 {
-					addressModel, err = bot.dao.CreateAddress(models.Address{
-						Address: address,
-					})
-					if err != nil {
-						return fmt.Errorf("dao.CreateAddress: %s", err.Error())
-					}
-				} else {
-					addressModel = addresses[0]
-				}
-				alias := update.Message.Text
-				if len(alias) > 100 {
-					alias = alias[:100]
-				}
 				itemTypeAddress, ok := bot.GetCachedItem(user.ID, "type_address")
 				if !ok {
 					return bot.oops(user)
 				}
-				err = bot.dao.CreateUserAddress(models.UserAddress{
-					UserID:    user.ID,
-					AddressID: addressModel.ID,
-					Alias:     alias,
-					Type:      itemTypeAddress.(string),
-				})
-				if err != nil {
-					return fmt.Errorf("dao.CreateUserAddress: %s", err.Error())
+				err := bot.Subscribe(user, item.(string), update.Message.Text, itemTypeAddress.(string))
+				if err == ErrNotValidatorAccount {
+					err = bot.sendText(user, bot.dictionary.Get("t.not_validator_account", user.Lang))
+					if err != nil {
+						return fmt.Errorf("sendText: %s", err.Error())
+					}
+					return bot.openRoute(RouteStart, user)
 				}
-
-				switch itemTypeAddress.(string) {
-				case models.AddressTypeValidator:
-					bot.addValidatorAddress(user, addressModel)
-				case models.AddressTypeAccount:
-					bot.addAccountAddress(user, addressModel)
+				if err != nil {
+					return fmt.Errorf("Subscribe: %s", err.Error())
 				}
 
 				msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.address_added", user.Lang))
@@ -383,6 +460,13 @@ func (bot *Bot) SetRoutes() {
 				if err != nil {
 					return fmt.Errorf("api.Send: %s", err.Error())
 				}
+				if itemTypeAddress.(string) == models.AddressTypeContract {
+					err = bot.openRoute(RouteContractFilter, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				}
 				err = bot.openRoute(RouteStart, user)
 				if err != nil {
 					return fmt.Errorf("openRoute: %s", err.Error())
@@ -453,5 +537,407 @@ func (bot *Bot) SetRoutes() {
 				return nil
 			},
 		},
+		RouteWebhook: {
+			request: func(user models.User) error {
+				var keyboard = tgbotapi.NewReplyKeyboard(
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.remove_webhook", user.Lang)),
+					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.cancel", user.Lang)),
+					),
+				)
+				msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.paste_webhook", user.Lang))
+				msg.ReplyMarkup = keyboard
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				return nil
+			},
+			response: func(update tgbotapi.Update, user models.User) error {
+				text := strings.TrimSpace(update.Message.Text)
+				if text == bot.dictionary.Get("b.cancel", user.Lang) {
+					err := bot.openRoute(RouteStart, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				}
+				// the webhook of a node follow or of an address subscription
+				item, _ := bot.GetCachedItem(user.ID, "webhook_node")
+				subject, _ := item.(string)
+				save := func(webhookURL string) (models.User, error) {
+					return bot.saveNodeWebhook(user, subject, webhookURL)
+				}
+				if subject == "" {
+					item, ok := bot.GetCachedItem(user.ID, "address")
+					if !ok {
+						return bot.oops(user)
+					}
+					subject = item.(string)
+					save = func(webhookURL string) (models.User, error) {
+						return bot.saveWebhook(user, subject, webhookURL)
+					}
+				}
+				var txt string
+				if text == bot.dictionary.Get("b.remove_webhook", user.Lang) {
+					_, err := save("")
+					if err != nil {
+						return fmt.Errorf("saveWebhook: %s", err.Error())
+					}
+					txt = bot.dictionary.Get("t.webhook_removed", user.Lang)
+				} else {
+					if !validWebhookURL(text) {
+						msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.invalid_webhook", user.Lang))
+						_, err := bot.api.Send(msg)
+						if err != nil {
+							return fmt.Errorf("api.Send: %s", err.Error())
+						}
+						return nil
+					}
+					var err error
+					user, err = save(text)
+					if err != nil {
+						return fmt.Errorf("saveWebhook: %s", err.Error())
+					}
+					txt = fmt.Sprintf(bot.dictionary.Get("t.webhook_saved", user.Lang), subject, text, user.WebhookSecret)
+				}
+				msg := tgbotapi.NewMessage(user.TgID, txt)
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				err = bot.openRoute(RouteStart, user)
+				if err != nil {
+					return fmt.Errorf("openRoute: %s", err.Error())
+				}
+				return nil
+			},
+		},
+		RouteSearchValidator: {
+			request: func(user models.User) error {
+				var keyboard = tgbotapi.NewReplyKeyboard(
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.show_all", user.Lang)),
+					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.return_back", user.Lang)),
+					),
+				)
+				msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.search_validator", user.Lang))
+				msg.ReplyMarkup = keyboard
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				return nil
+			},
+			response: func(update tgbotapi.Update, user models.User) error {
+				query := strings.TrimSpace(update.Message.Text)
+				switch query {
+				case bot.dictionary.Get("b.return_back", user.Lang):
+					err := bot.openRoute(RouteStart, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				case bot.dictionary.Get("b.show_all", user.Lang):
+					query = ""
+				}
+				bot.SetCachedItem(user.ID, "node_query", query)
+				err := bot.sendNodePicker(user, query, 0, 0)
+				if err != nil {
+					return fmt.Errorf("sendNodePicker: %s", err.Error())
+				}
+				return nil
+			},
+		},
+		RouteChangeWhaleShare: {
+			request: func(user models.User) error {
+				var keyboard = tgbotapi.NewReplyKeyboard(
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.return_back", user.Lang)),
+					),
+				)
+				text := fmt.Sprintf(bot.dictionary.Get("t.paste_whale_share", user.Lang), user.WhaleShare.String())
+				msg := tgbotapi.NewMessage(user.TgID, text)
+				msg.ReplyMarkup = keyboard
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				return nil
+			},
+			response: func(update tgbotapi.Update, user models.User) error {
+				msg := update.Message.Text
+				if msg == bot.dictionary.Get("b.return_back", user.Lang) {
+					err := bot.openRoute(RouteSettings, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				}
+				share, err := decimal.NewFromString(strings.TrimSuffix(strings.TrimSpace(msg), "%"))
+				if err != nil || share.IsNegative() || share.GreaterThan(hundred) {
+					msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.invalid_whale_share", user.Lang))
+					_, err := bot.api.Send(msg)
+					if err != nil {
+						return fmt.Errorf("api.Send: %s", err.Error())
+					}
+					return nil
+				}
+				user.WhaleShare = share.Truncate(2)
+				err = bot.dao.UpdateUser(user)
+				if err != nil {
+					return fmt.Errorf("dao.UpdateUser: %s", err.Error())
+				}
+				tgMsg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.successful_updated", user.Lang))
+				_, err = bot.api.Send(tgMsg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				bot.updateUserSettings(user)
+				err = bot.openRoute(RouteSettings, user)
+				if err != nil {
+					return fmt.Errorf("openRoute: %s", err.Error())
+				}
+				return nil
+			},
+		},
+		RouteChangeStability: {
+			request: func(user models.User) error {
+				var keyboard = tgbotapi.NewReplyKeyboard(
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.return_back", user.Lang)),
+					),
+				)
+				text := fmt.Sprintf(
+					bot.dictionary.Get("t.paste_stability", user.Lang),
+					user.StabilityLevel.String(),
+					user.StabilityDrop.String(),
+				)
+				msg := tgbotapi.NewMessage(user.TgID, text)
+				msg.ReplyMarkup = keyboard
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				return nil
+			},
+			response: func(update tgbotapi.Update, user models.User) error {
+				msg := update.Message.Text
+				if msg == bot.dictionary.Get("b.return_back", user.Lang) {
+					err := bot.openRoute(RouteSettings, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				}
+				level, drop, ok := parseStabilitySettings(msg)
+				if !ok {
+					msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.invalid_stability", user.Lang))
+					_, err := bot.api.Send(msg)
+					if err != nil {
+						return fmt.Errorf("api.Send: %s", err.Error())
+					}
+					return nil
+				}
+				user.StabilityLevel = level.Truncate(4)
+				user.StabilityDrop = drop.Truncate(4)
+				err := bot.dao.UpdateUser(user)
+				if err != nil {
+					return fmt.Errorf("dao.UpdateUser: %s", err.Error())
+				}
+				tgMsg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.successful_updated", user.Lang))
+				_, err = bot.api.Send(tgMsg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				bot.updateUserSettings(user)
+				err = bot.openRoute(RouteSettings, user)
+				if err != nil {
+					return fmt.Errorf("openRoute: %s", err.Error())
+				}
+				return nil
+			},
+		},
+		RouteAddPriceAlert: {
+			request: func(user models.User) error {
+				var keyboard = tgbotapi.NewReplyKeyboard(
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.return_back", user.Lang)),
+					),
+				)
+				text := fmt.Sprintf(
+					bot.dictionary.Get("t.paste_price_alert", user.Lang),
+					bot.fiatValue(bot.market.GetNASPrice(), user.Currency, pricePlaces),
+					bot.fiatValue(bot.market.GetNAXPrice(), user.Currency, pricePlaces),
+					user.Currency,
+				) + bot.stalePricesText(user.Lang)
+				msg := tgbotapi.NewMessage(user.TgID, text)
+				msg.ReplyMarkup = keyboard
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				return nil
+			},
+			response: func(update tgbotapi.Update, user models.User) error {
+				msg := update.Message.Text
+				if msg == bot.dictionary.Get("b.return_back", user.Lang) {
+					err := bot.openRoute(RouteStart, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				}
+				alert, ok := parsePriceAlert(msg)
+				if !ok {
+					msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.invalid_price_alert", user.Lang))
+					_, err := bot.api.Send(msg)
+					if err != nil {
+						return fmt.Errorf("api.Send: %s", err.Error())
+					}
+					return nil
+				}
+				err := bot.openRoute(RouteStart, user)
+				if err != nil {
+					return fmt.Errorf("openRoute: %s", err.Error())
+				}
+				err = bot.addPriceAlert(user, alert)
+				if err != nil {
+					return fmt.Errorf("addPriceAlert: %s", err.Error())
+				}
+				return nil
+			},
+		},
+		RouteChangeCurrency: {
+			request: func(user models.User) error {
+				var rows [][]tgbotapi.KeyboardButton
+				var row []tgbotapi.KeyboardButton
+				for _, currency := range market.Currencies {
+					row = append(row, tgbotapi.NewKeyboardButton(currency))
+					if len(row) == 3 {
+						rows = append(rows, tgbotapi.NewKeyboardButtonRow(row...))
+						row = nil
+					}
+				}
+				if len(row) != 0 {
+					rows = append(rows, tgbotapi.NewKeyboardButtonRow(row...))
+				}
+				rows = append(rows, tgbotapi.NewKeyboardButtonRow(
+					tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.return_back", user.Lang)),
+				))
+				text := fmt.Sprintf(bot.dictionary.Get("t.choose_currency", user.Lang), user.Currency)
+				msg := tgbotapi.NewMessage(user.TgID, text)
+				msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(rows...)
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				return nil
+			},
+			response: func(update tgbotapi.Update, user models.User) error {
+				msg := strings.ToUpper(strings.TrimSpace(update.Message.Text))
+				if update.Message.Text == bot.dictionary.Get("b.return_back", user.Lang) {
+					err := bot.openRoute(RouteSettings, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				}
+				found := false
+				for _, currency := range market.Currencies {
+					if msg == currency {
+						found = true
+						break
+					}
+				}
+				if !found {
+					msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.wrong_currency", user.Lang))
+					_, err := bot.api.Send(msg)
+					if err != nil {
+						return fmt.Errorf("api.Send: %s", err.Error())
+					}
+					return nil
+				}
+				user.Currency = msg
+				err := bot.dao.UpdateUser(user)
+				if err != nil {
+					return fmt.Errorf("dao.UpdateUser: %s", err.Error())
+				}
+				tgMsg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.successful_updated", user.Lang))
+				_, err = bot.api.Send(tgMsg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				bot.updateUserSettings(user)
+				err = bot.openRoute(RouteSettings, user)
+				if err != nil {
+					return fmt.Errorf("openRoute: %s", err.Error())
+				}
+				return nil
+			},
+		},
+		RouteContractFilter: {
+			request: func(user models.User) error {
+				var keyboard = tgbotapi.NewReplyKeyboard(
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.all_calls", user.Lang)),
+					),
+					tgbotapi.NewKeyboardButtonRow(
+						tgbotapi.NewKeyboardButton(bot.dictionary.Get("b.cancel", user.Lang)),
+					),
+				)
+				msg := tgbotapi.NewMessage(user.TgID, bot.dictionary.Get("t.enter_contract_filter", user.Lang))
+				msg.ReplyMarkup = keyboard
+				_, err := bot.api.Send(msg)
+				if err != nil {
+					return fmt.Errorf("api.Send: %s", err.Error())
+				}
+				return nil
+			},
+			response: func(update tgbotapi.Update, user models.User) error {
+				text := strings.TrimSpace(update.Message.Text)
+				if text == bot.dictionary.Get("b.cancel", user.Lang) {
+					err := bot.openRoute(RouteStart, user)
+					if err != nil {
+						return fmt.Errorf("openRoute: %s", err.Error())
+					}
+					return nil
+				}
+				item, ok := bot.GetCachedItem(user.ID, "address")
+				if !ok {
+					return bot.oops(user)
+				}
+				if text == bot.dictionary.Get("b.all_calls", user.Lang) {
+					text = contractAllCalls
+				}
+				filter, ok := parseContractFilter(text)
+				if !ok {
+					return bot.sendText(user, bot.dictionary.Get("t.wrong_contract_filter", user.Lang))
+				}
+				err := bot.setContractFilter(user, item.(string), filter)
+				if err != nil {
+					if err == ErrNotContractSubscription || err.Error() == derrors.ErrNotFound {
+						return bot.oops(user)
+					}
+					return fmt.Errorf("setContractFilter: %s", err.Error())
+				}
+				err = bot.sendText(user, fmt.Sprintf(
+					bot.dictionary.Get("t.contract_filter_saved", user.Lang),
+					bot.contractFilterText(filter, user.Lang),
+				))
+				if err != nil {
+					return fmt.Errorf("sendText: %s", err.Error())
+				}
+				err = bot.openRoute(RouteStart, user)
+				if err != nil {
+					return fmt.Errorf("openRoute: %s", err.Error())
+				}
+				return nil
+			},
+		},
 	}
 }
@@ -0,0 +1,192 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+var (
+	defaultStabilityLevel = decimal.New(95, -2)
+	defaultStabilityDrop  = decimal.New(1, -2)
+	// stabilityHysteresis is added to the alert threshold before the node is reported as recovered.
+	stabilityHysteresis = decimal.New(2, -2)
+	maxStabilityIndex   = decimal.New(1, 0)
+)
+
+// stabilityHistoryRetention is how long the stored indexes are kept.
+const stabilityHistoryRetention = time.Hour * 24 * 30
+
+// setStabilityIndexes restores the last known indexes and the active alerts after restart.
+func (bot *Bot) setStabilityIndexes() error {
+	indexes, err := bot.dao.GetLastStabilityIndexes()
+	if err != nil {
+		return fmt.Errorf("dao.GetLastStabilityIndexes: %s", err.Error())
+	}
+	alerts, err := bot.dao.GetStabilityAlerts()
+	if err != nil {
+		return fmt.Errorf("dao.GetStabilityAlerts: %s", err.Error())
+	}
+	bot.mu.Lock()
+	for _, item := range indexes {
+		bot.lastStabilityIndexes[item.NodeID] = item.Value
+	}
+	for _, alert := range alerts {
+		_, ok := bot.stabilityAlerts[alert.NodeID]
+		if !ok {
+			bot.stabilityAlerts[alert.NodeID] = make(map[uint64]decimal.Decimal)
+		}
+		bot.stabilityAlerts[alert.NodeID][alert.UserID] = alert.RecoverLevel
+	}
+	bot.mu.Unlock()
+	return nil
+}
+
+// checkStabilityIndexes stores changed indexes and alerts users when the index falls below their level
+// or drops by their drop size since the previous sample. An alerted user is not alerted again
+// until the node is back above the recover level.
+func (bot *Bot) checkStabilityIndexes() {
+	var (
+		messages []tgbotapi.MessageConfig
+		history  []models.StabilityIndex
+		created  []models.StabilityAlert
+		deleted  []models.StabilityAlert
+	)
+	events := make(map[string]webhook.StabilityData) // [nodeID]
+	eventAddresses := make(map[string][]string)      // [nodeID]
+	bot.mu.Lock()
+	for _, n := range bot.nodes {
+		prev, hasPrev := bot.lastStabilityIndexes[n.ID]
+		if !hasPrev || prev != n.StabilityIndex {
+			history = append(history, models.StabilityIndex{NodeID: n.ID, Value: n.StabilityIndex})
+		}
+		bot.lastStabilityIndexes[n.ID] = n.StabilityIndex
+		if hasPrev && n.StabilityIndex < prev {
+			events[n.ID] = webhook.StabilityData{
+				NodeID:         n.ID,
+				StabilityIndex: n.StabilityIndex,
+				Previous:       prev,
+			}
+			eventAddresses[n.ID] = nodeAddresses(n)
+		}
+
+		index := decimal.NewFromFloat(n.StabilityIndex)
+		for _, user := range bot.nodeUsers(n) {
+			recoverLevel, alerted := bot.stabilityAlerts[n.ID][user.ID]
+			if alerted {
+				if index.LessThan(recoverLevel) {
+					continue
+				}
+				delete(bot.stabilityAlerts[n.ID], user.ID)
+				deleted = append(deleted, models.StabilityAlert{UserID: user.ID, NodeID: n.ID})
+				if !user.Mute {
+					text := fmt.Sprintf(bot.dictionary.Get("t.stability_recovered", user.Lang), n.ID, n.StabilityIndex)
+					messages = append(messages, tgbotapi.NewMessage(user.TgID, text))
+				}
+				continue
+			}
+			belowLevel := user.StabilityLevel.IsPositive() && index.LessThan(user.StabilityLevel)
+			dropped := hasPrev && user.StabilityDrop.IsPositive() &&
+				decimal.NewFromFloat(prev).Sub(index).GreaterThanOrEqual(user.StabilityDrop)
+			if !belowLevel && !dropped {
+				continue
+			}
+			recoverLevel = stabilityRecoverLevel(user, decimal.NewFromFloat(prev), dropped)
+			_, ok := bot.stabilityAlerts[n.ID]
+			if !ok {
+				bot.stabilityAlerts[n.ID] = make(map[uint64]decimal.Decimal)
+			}
+			bot.stabilityAlerts[n.ID][user.ID] = recoverLevel
+			created = append(created, models.StabilityAlert{UserID: user.ID, NodeID: n.ID, RecoverLevel: recoverLevel})
+			if user.Mute {
+				continue
+			}
+			var text string
+			if dropped {
+				text = fmt.Sprintf(bot.dictionary.Get("t.stability_dropped", user.Lang), n.ID, prev, n.StabilityIndex)
+			} else {
+				text = fmt.Sprintf(bot.dictionary.Get("t.stability_below_level", user.Lang), n.ID, n.StabilityIndex, user.StabilityLevel.String())
+			}
+			messages = append(messages, tgbotapi.NewMessage(user.TgID, text))
+		}
+	}
+	bot.mu.Unlock()
+
+	err := bot.dao.CreateStabilityIndexes(history)
+	if err != nil {
+		log.Error("Bot: checkStabilityIndexes: dao.CreateStabilityIndexes: %s", err.Error())
+	}
+	for _, alert := range deleted {
+		err = bot.dao.DeleteStabilityAlert(alert.UserID, alert.NodeID)
+		if err != nil {
+			log.Error("Bot: checkStabilityIndexes: dao.DeleteStabilityAlert: %s", err.Error())
+		}
+	}
+	for _, alert := range created {
+		err = bot.dao.CreateStabilityAlert(alert)
+		if err != nil {
+			log.Error("Bot: checkStabilityIndexes: dao.CreateStabilityAlert: %s", err.Error())
+		}
+	}
+
+	for nodeID, data := range events {
+		bot.sendNodeWebhooks(nodeID, eventAddresses[nodeID], webhook.Event{
+			Event: webhook.EventStabilityChange,
+			Data:  data,
+		})
+	}
+
+	for _, msg := range messages {
+		_, err := bot.api.Send(msg)
+		if err != nil {
+			log.Error("Bot: checkStabilityIndexes: api.Send: %s", err.Error())
+		}
+	}
+}
+
+// pruneStabilityIndexes deletes the indexes older than stabilityHistoryRetention.
+func (bot *Bot) pruneStabilityIndexes() {
+	err := bot.dao.DeleteStabilityIndexes(time.Now().Add(-stabilityHistoryRetention))
+	if err != nil {
+		log.Error("Bot: pruneStabilityIndexes: dao.DeleteStabilityIndexes: %s", err.Error())
+	}
+}
+
+// stabilityRecoverLevel returns the index the node has to reach to be reported as recovered.
+func stabilityRecoverLevel(user models.User, prev decimal.Decimal, dropped bool) decimal.Decimal {
+	level := decimal.Zero
+	if user.StabilityLevel.IsPositive() {
+		level = user.StabilityLevel
+	}
+	if dropped {
+		level = decimal.Max(level, prev.Sub(user.StabilityDrop))
+	}
+	return decimal.Min(level.Add(stabilityHysteresis), maxStabilityIndex)
+}
+
+// parseStabilitySettings parses `<level> <drop>`, both values must be within [0, 1].
+func parseStabilitySettings(text string) (level decimal.Decimal, drop decimal.Decimal, ok bool) {
+	parts := strings.Fields(text)
+	if len(parts) != 2 {
+		return level, drop, false
+	}
+	level, err := decimal.NewFromString(parts[0])
+	if err != nil {
+		return level, drop, false
+	}
+	drop, err = decimal.NewFromString(parts[1])
+	if err != nil {
+		return level, drop, false
+	}
+	for _, d := range []decimal.Decimal{level, drop} {
+		if d.IsNegative() || d.GreaterThan(maxStabilityIndex) {
+			return level, drop, false
+		}
+	}
+	return level, drop, true
+}
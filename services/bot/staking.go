@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"strings"
+)
+
+// stakingEventNotify announces staking contract calls other than votes and transfers to the watchers
+// of the sender, of the node and of the account set by the call.
+func (bot *Bot) stakingEventNotify(tx node.Transaction, call node.StakingCall) {
+	addresses := []string{tx.From}
+	bot.mu.RLock()
+	users := bot.addressUsers(tx.From)
+	if call.NodeID != "" {
+		n, ok := bot.nodes[call.NodeID]
+		if !ok {
+			n.ID = call.NodeID // a new node is known to its followers only
+		}
+		for userID, user := range bot.nodeUsers(n) {
+			users[userID] = user
+		}
+		addresses = append(addresses, nodeAddresses(n)...)
+	}
+	if call.Address != "" {
+		for userID, user := range bot.addressUsers(call.Address) {
+			users[userID] = user
+		}
+		addresses = append(addresses, call.Address)
+	}
+	bot.mu.RUnlock()
+
+	bot.sendNodeWebhooks(call.NodeID, addresses, webhook.Event{
+		Event: webhook.EventStaking,
+		Data: webhook.StakingData{
+			Function:    call.Function,
+			Kind:        call.Kind,
+			NodeID:      call.NodeID,
+			Account:     call.Address,
+			Role:        call.Role,
+			Value:       call.Amount(),
+			Args:        call.Args,
+			Transaction: tx,
+		},
+	})
+	for _, user := range users {
+		if user.Mute {
+			continue
+		}
+		text := fmt.Sprintf(
+			bot.dictionary.Get("t.staking_"+call.Kind, user.Lang),
+			tx.From,
+			call.NodeID,
+			bot.stakingDetails(call, user.Lang),
+		)
+		err := bot.sendMsg(tgbotapi.NewMessage(user.TgID, text))
+		if err != nil {
+			log.Error("Bot: stakingEventNotify: api.Send: %s", err.Error())
+		}
+	}
+}
+
+func (bot *Bot) stakingDetails(call node.StakingCall, lang string) string {
+	switch call.Kind {
+	case node.StakingManagerChange:
+		return fmt.Sprintf("%s: %s", bot.dictionary.Get("t.role_"+call.Role, lang), call.Address)
+	case node.StakingStake, node.StakingUnstake:
+		return fmt.Sprintf("%s %s", call.Amount().Truncate(4).String(), call.Token)
+	case node.StakingUnknown:
+		return fmt.Sprintf("%s(%s)", call.Function, strings.Join(call.Args, ", "))
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// stateCacheTTL limits the age of the cached balances, changes that are not seen
+// by the scanner (e.g. the node votes of a validator account) show up after it.
+const stateCacheTTL = time.Minute * 10
+
+type addressBalances struct {
+	nas         decimal.Decimal
+	nax         decimal.Decimal
+	votedAmount decimal.Decimal
+	// partial is set when the votes lookup failed, such balances are not cached
+	partial  bool
+	height   uint64 // last parsed block when the balances were read
+	cachedAt time.Time
+}
+
+// getAddressBalances returns the cached balances while no parsed tx touched the address since they were read.
+func (bot *Bot) getAddressBalances(address string) (balances addressBalances, err error) {
+	bot.mu.RLock()
+	cached, ok := bot.stateCache[address]
+	height := bot.parsedHeight
+	touched := bot.stateTouched[address]
+	bot.mu.RUnlock()
+	if ok && cached.height >= touched && time.Since(cached.cachedAt) < stateCacheTTL {
+		return cached, nil
+	}
+	balances, err = bot.fetchAddressBalances(address)
+	if err != nil {
+		return balances, err
+	}
+	balances.height = height
+	balances.cachedAt = time.Now()
+	bot.mu.Lock()
+	// a tx parsed during the lookup may be missing in the balances
+	if !balances.partial && bot.stateTouched[address] <= height {
+		bot.stateCache[address] = balances
+	}
+	bot.mu.Unlock()
+	return balances, nil
+}
+
+func (bot *Bot) fetchAddressBalances(address string) (balances addressBalances, err error) {
+	as, err := bot.node.GetAccountState(address)
+	if err != nil {
+		return balances, fmt.Errorf("node.GetAccountState: %s", err.Error())
+	}
+	balances.nas = as.Result.Balance.Div(node.PrecisionDivNAS)
+	naxBalance, err := bot.node.GetNAXBalance(address)
+	if err != nil {
+		return balances, fmt.Errorf("node.GetNAXBalance: %s", err.Error())
+	}
+	balances.nax = naxBalance.Div(node.PrecisionDivNAX)
+	votedAmount, err := bot.node.GetVotedNAX(address)
+	if err != nil {
+		log.Error("Bot: fetchAddressBalances: node.GetVotedNAX: %s", err.Error())
+		balances.partial = true
+		return balances, nil
+	}
+	balances.votedAmount = votedAmount.Div(node.PrecisionDivNAX)
+	return balances, nil
+}
+
+// invalidateTxStates drops the cached balances of the subscribed addresses the tx touches:
+// the sender, the recipient, the accounts of a NAX transfer and the accounts of a staking call and its node.
+func (bot *Bot) invalidateTxStates(h uint64, tx node.Transaction) {
+	addresses := []string{tx.From, tx.To}
+	var call node.StakingCall
+	var err error
+	if tx.Type == node.TxTypeCall && (tx.To == node.NAXContract || tx.To == StakingContract) {
+		call, err = node.DecodeStakingCall(tx.Data)
+		if err != nil {
+			log.Warn("Bot: invalidateTxStates: node.DecodeStakingCall(%s): %s", tx.Hash, err.Error())
+		}
+	}
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if err == nil && tx.To == node.NAXContract {
+		addresses = append(addresses, naxCallAddresses(call)...)
+	}
+	if err == nil && tx.To == StakingContract {
+		if call.Address != "" {
+			addresses = append(addresses, call.Address)
+		}
+		n, ok := bot.nodes[call.NodeID]
+		if ok {
+			addresses = append(addresses, nodeAddresses(n)...)
+		}
+	}
+	for _, address := range addresses {
+		if len(bot.addresses[address]) == 0 {
+			continue
+		}
+		delete(bot.stateCache, address)
+		bot.stateTouched[address] = h
+	}
+}
+
+// naxCallAddresses returns the accounts whose NAX balance the NAX contract call moves besides the sender:
+// the recipient of transfer(to, value) and the owner and the recipient of transferFrom(from, to, value).
+func naxCallAddresses(call node.StakingCall) []string {
+	switch call.Function {
+	case "transfer":
+		if len(call.Args) > 0 {
+			return call.Args[:1]
+		}
+	case "transferFrom":
+		if len(call.Args) > 1 {
+			return call.Args[:2]
+		}
+	}
+	return nil
+}
+
+func (bot *Bot) setParsedHeight(h uint64) {
+	bot.mu.Lock()
+	bot.parsedHeight = h
+	bot.mu.Unlock()
+}
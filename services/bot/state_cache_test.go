@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/everstake/nebulas-tg-bot/config"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"testing"
+)
+
+func encodeTestCall(function string, args string) string {
+	data, _ := json.Marshal(node.CallContract{Function: function, Args: args})
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestInvalidateTxStates(t *testing.T) {
+	const (
+		sender    = "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"
+		recipient = "n1Jkdiq1H1HSXYJXtvDDkYm84Tmapo4hhMv"
+		owner     = "n1owner"
+		other     = "n1other"
+	)
+	tests := []struct {
+		name    string
+		tx      node.Transaction
+		touched []string
+	}{
+		{
+			name:    "nax transfer",
+			tx:      node.Transaction{From: sender, To: node.NAXContract, Type: node.TxTypeCall, Data: encodeTestCall("transfer", `["`+recipient+`", "10"]`)},
+			touched: []string{sender, recipient},
+		},
+		{
+			name:    "nax transferFrom",
+			tx:      node.Transaction{From: sender, To: node.NAXContract, Type: node.TxTypeCall, Data: encodeTestCall("transferFrom", `["`+owner+`", "`+recipient+`", "10"]`)},
+			touched: []string{sender, owner, recipient},
+		},
+		{
+			name:    "nax approve",
+			tx:      node.Transaction{From: sender, To: node.NAXContract, Type: node.TxTypeCall, Data: encodeTestCall("approve", `["`+recipient+`", "10"]`)},
+			touched: []string{sender},
+		},
+		{
+			name:    "transfer of another token",
+			tx:      node.Transaction{From: sender, To: other, Type: node.TxTypeCall, Data: encodeTestCall("transfer", `["`+recipient+`", "10"]`)},
+			touched: []string{sender, other},
+		},
+		{
+			name:    "nas transfer",
+			tx:      node.Transaction{From: sender, To: recipient, Type: node.TxTypeBinary},
+			touched: []string{sender, recipient},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, _ := newTestBot(config.Config{})
+			for _, address := range []string{sender, recipient, owner, other} {
+				bot.addresses[address] = map[uint64]struct{}{1: {}}
+				bot.stateCache[address] = addressBalances{height: 1}
+			}
+			bot.invalidateTxStates(2, test.tx)
+			touched := make(map[string]bool)
+			for _, address := range test.touched {
+				touched[address] = true
+			}
+			for _, address := range []string{sender, recipient, owner, other} {
+				_, cached := bot.stateCache[address]
+				if cached == touched[address] {
+					t.Errorf("%s cached = %t, want %t", address, cached, !touched[address])
+				}
+				if touched[address] && bot.stateTouched[address] != 2 {
+					t.Errorf("%s touched at %d, want 2", address, bot.stateTouched[address])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"time"
+)
+
+const (
+	pollingInterval = time.Second * 2
+	// streamPollingInterval is a safety net while the stream is connected, the stream wakes Parsing up earlier.
+	streamPollingInterval = time.Second * 30
+)
+
+// listenStream wakes Parsing up when the node reports a new irreversible block.
+func (bot *Bot) listenStream() {
+	for event := range bot.stream.Events() {
+		if event.Topic != node.TopicLatestIrreversibleBlock {
+			continue
+		}
+		select {
+		case bot.newBlock <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// waitBlock waits for a new irreversible block, the node is polled when the stream is down.
+// A failed parsing is retried on pollingInterval, the stream only reports new blocks.
+func (bot *Bot) waitBlock(retry bool) {
+	timeout := pollingInterval
+	if !retry && bot.stream != nil && bot.stream.Connected() {
+		timeout = streamPollingInterval
+	}
+	select {
+	case <-bot.newBlock:
+	case <-time.After(timeout):
+	}
+}
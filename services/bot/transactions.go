@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/shopspring/decimal"
+	"html"
+	"strings"
+	"time"
+)
+
+// maxCallArgLength truncates long arguments (e.g. objects) of the decoded calls.
+const maxCallArgLength = 64
+
+// transactionText renders the NAS transaction notification in the Telegram HTML mode.
+func (bot *Bot) transactionText(user models.User, tx node.Transaction, value decimal.Decimal, status string) string {
+	aliases := bot.userAliases(user)
+	nasPrice := bot.market.GetNASPrice()
+	fee := tx.Fee()
+	return html.EscapeString(bot.transferLabelsText(user, aliases, tx.From, tx.To)) + fmt.Sprintf(
+		bot.dictionary.Get("t.transaction", user.Lang),
+		tx.Hash,
+		tx.From,
+		tx.To,
+		value.Truncate(4).String(),
+		bot.fiatValue(value.Mul(nasPrice), user.Currency, 4),
+		bot.payloadText(user, tx, aliases),
+		fee.String(),
+		bot.fiatValue(fee.Mul(nasPrice), user.Currency, 6),
+		tx.BlockHeight,
+		status,
+		tx.GasPrice.String(),
+		tx.GasUsed.String(),
+		tx.Nonce,
+		tx.Type,
+		time.Unix(tx.Timestamp, 0).String(),
+	)
+}
+
+// payloadText describes the contract call or deploy of the transaction, empty for the other types.
+func (bot *Bot) payloadText(user models.User, tx node.Transaction, aliases map[string]string) string {
+	if tx.Type != node.TxTypeCall && tx.Type != node.TxTypeDeploy {
+		return ""
+	}
+	payload, err := node.DecodePayload(tx)
+	if err != nil {
+		log.Warn("Bot: payloadText: node.DecodePayload(%s): %s", tx.Hash, err.Error())
+		return ""
+	}
+	args := callArgsText(payload.Args)
+	if tx.Type == node.TxTypeDeploy {
+		return fmt.Sprintf(
+			bot.dictionary.Get("t.tx_deploy", user.Lang),
+			html.EscapeString(payload.SourceType),
+			payload.SourceSize,
+			args,
+		)
+	}
+	contract := bot.addressLabel(tx.To, aliases, user.Lang)
+	if contract == "" {
+		contract = shortAddress(tx.To)
+	}
+	return fmt.Sprintf(
+		bot.dictionary.Get("t.tx_call", user.Lang),
+		html.EscapeString(contract),
+		html.EscapeString(payload.Function),
+		args,
+	)
+}
+
+// callArgsText joins the escaped call arguments, long ones are truncated.
+func callArgsText(args []string) string {
+	items := make([]string, len(args))
+	for i, arg := range args {
+		if runes := []rune(arg); len(runes) > maxCallArgLength {
+			arg = string(runes[:maxCallArgLength]) + "…"
+		}
+		items[i] = html.EscapeString(arg)
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	validatorsPageSize = 10
+
+	sortByVotes     = "votes"
+	sortByStaking   = "staking"
+	sortByStability = "stability"
+	sortByName      = "name"
+)
+
+var validatorsSorts = []string{sortByVotes, sortByStaking, sortByStability, sortByName}
+
+func (bot *Bot) sortedNodes(sortBy string) []node.ValidatorNode {
+	list := bot.findNodes("")
+	sort.SliceStable(list, func(i, j int) bool {
+		switch sortBy {
+		case sortByVotes:
+			return list[i].VoteValue.GreaterThan(list[j].VoteValue)
+		case sortByStaking:
+			return list[i].StakingValue.GreaterThan(list[j].StakingValue)
+		case sortByStability:
+			return list[i].StabilityIndex > list[j].StabilityIndex
+		}
+		return false // findNodes already sorted by name
+	})
+	return list
+}
+
+// sendValidators shows a page of the validator directory, the message is edited when messageID is set.
+func (bot *Bot) sendValidators(user models.User, sortBy string, page int, messageID int) error {
+	list := bot.sortedNodes(sortBy)
+	if len(list) == 0 {
+		return bot.sendText(user, bot.dictionary.Get("t.validators_not_found", user.Lang))
+	}
+	pages := (len(list) + validatorsPageSize - 1) / validatorsPageSize
+	if page < 0 || page >= pages {
+		page = 0
+	}
+	offset := page * validatorsPageSize
+	lines := []string{fmt.Sprintf(bot.dictionary.Get("t.validators", user.Lang), len(list), page+1, pages)}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, n := range list[offset:minInt(offset+validatorsPageSize, len(list))] {
+		lines = append(lines, fmt.Sprintf(
+			bot.dictionary.Get("t.validators_item", user.Lang),
+			offset+i+1,
+			nodeTitle(n),
+			bot.nodeTypeName(n.Type, user.Lang),
+			nodeStatusIcons(n),
+			n.VoteValue.Div(node.PrecisionDivNAX).Truncate(0).String(),
+			n.StakingValue.Div(node.PrecisionDivNAS).Truncate(0).String(),
+			n.StabilityIndex,
+		))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d. %s", offset+i+1, nodeTitle(n)), fmt.Sprintf("val_%s", n.ID)),
+		))
+	}
+	var sortRow []tgbotapi.InlineKeyboardButton
+	for _, s := range validatorsSorts {
+		title := bot.dictionary.Get("b.sort_"+s, user.Lang)
+		if s == sortBy {
+			title = "• " + title
+		}
+		sortRow = append(sortRow, tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("vals_%s_0", s)))
+	}
+	rows = append([][]tgbotapi.InlineKeyboardButton{sortRow}, rows...)
+	if nav := paginationRow("vals_"+sortBy, page, pages); len(nav) != 0 {
+		rows = append(rows, nav)
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	text := strings.Join(lines, "\n\n")
+	if messageID != 0 {
+		msg := tgbotapi.NewEditMessageText(user.TgID, messageID, text)
+		msg.ReplyMarkup = &markup
+		_, err := bot.api.Send(msg)
+		if err != nil {
+			return fmt.Errorf("api.Send: %s", err.Error())
+		}
+		return nil
+	}
+	msg := tgbotapi.NewMessage(user.TgID, text)
+	msg.ReplyMarkup = markup
+	_, err := bot.api.Send(msg)
+	if err != nil {
+		return fmt.Errorf("api.Send: %s", err.Error())
+	}
+	return nil
+}
+
+// handleValidatorsPage parses `<sort>_<page>` callback payload.
+func (bot *Bot) handleValidatorsPage(user models.User, payload string, messageID int) error {
+	sortBy := sortByVotes
+	page := 0
+	i := strings.LastIndex(payload, "_")
+	if i > 0 {
+		sortBy = payload[:i]
+		page, _ = strconv.Atoi(payload[i+1:])
+	}
+	return bot.sendValidators(user, sortBy, page, messageID)
+}
+
+func (bot *Bot) sendValidatorCard(user models.User, nodeID string) error {
+	bot.mu.RLock()
+	n, ok := bot.nodes[nodeID]
+	bot.mu.RUnlock()
+	if !ok {
+		return bot.sendText(user, bot.dictionary.Get("t.validators_not_found", user.Lang))
+	}
+	var keyboard = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.voters", user.Lang), fmt.Sprintf("voters_%s", n.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(bot.dictionary.Get("b.follow", user.Lang), fmt.Sprintf("follow_%s", n.ID)),
+		),
+	)
+	msg := tgbotapi.NewMessage(user.TgID, bot.nodeCardText(n, user.Lang))
+	msg.ReplyMarkup = keyboard
+	_, err := bot.api.Send(msg)
+	if err != nil {
+		return fmt.Errorf("api.Send: %s", err.Error())
+	}
+	return nil
+}
+
+func nodeStatusIcons(n node.ValidatorNode) string {
+	icons := "🔴"
+	if n.Online {
+		icons = "🟢"
+	}
+	if n.Approved {
+		return icons + "✅"
+	}
+	return icons + "⛔️"
+}
@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	votesSnapshotBlocks = 5760 // ~ one day
+	topVotersCount      = 10
+)
+
+var hundred = decimal.New(100, 0)
+
+// nodeVotes returns node votes in NAX grouped by voter and sorted by value.
+func (bot *Bot) nodeVotes(nodeID string) (votes []node.Vote, total decimal.Decimal, err error) {
+	list, err := bot.node.GetNodeVotesList(nodeID)
+	if err != nil {
+		return nil, total, fmt.Errorf("node.GetNodeVotesList: %s", err.Error())
+	}
+	grouped := make(map[string]decimal.Decimal)
+	for _, vote := range list {
+		grouped[vote.Address] = grouped[vote.Address].Add(vote.Value.Div(node.PrecisionDivNAX))
+	}
+	for address, value := range grouped {
+		votes = append(votes, node.Vote{Address: address, Value: value})
+		total = total.Add(value)
+	}
+	sort.Slice(votes, func(i, j int) bool {
+		return votes[i].Value.GreaterThan(votes[j].Value)
+	})
+	return votes, total, nil
+}
+
+// snapshotVotes stores current votes of every node somebody watches.
+func (bot *Bot) snapshotVotes() {
+	var nodeIDs []string
+	bot.mu.RLock()
+	for _, n := range bot.nodes {
+		if len(bot.nodeUsers(n)) != 0 {
+			nodeIDs = append(nodeIDs, n.ID)
+		}
+	}
+	bot.mu.RUnlock()
+	for _, nodeID := range nodeIDs {
+		err := bot.snapshotNodeVotes(nodeID)
+		if err != nil {
+			log.Error("Bot: snapshotVotes: snapshotNodeVotes(%s): %s", nodeID, err.Error())
+		}
+	}
+}
+
+func (bot *Bot) snapshotNodeVotes(nodeID string) error {
+	votes, _, err := bot.nodeVotes(nodeID)
+	if err != nil {
+		return fmt.Errorf("nodeVotes: %s", err.Error())
+	}
+	items := make([]models.VoteSnapshot, 0, len(votes))
+	for _, vote := range votes {
+		items = append(items, models.VoteSnapshot{
+			NodeID:  nodeID,
+			Address: vote.Address,
+			Value:   vote.Value,
+		})
+	}
+	err = bot.dao.ReplaceVotesSnapshot(nodeID, items)
+	if err != nil {
+		return fmt.Errorf("dao.ReplaceVotesSnapshot: %s", err.Error())
+	}
+	return nil
+}
+
+// ensureVotesSnapshot takes the votes snapshot of a newly followed node, so the changes and the whale
+// alerts do not wait for the next daily snapshot.
+func (bot *Bot) ensureVotesSnapshot(nodeID string) error {
+	snapshot, err := bot.dao.GetVotesSnapshots(filters.VotesSnapshots{NodeIDs: []string{nodeID}})
+	if err != nil {
+		return fmt.Errorf("dao.GetVotesSnapshots: %s", err.Error())
+	}
+	if len(snapshot) != 0 {
+		return nil
+	}
+	return bot.snapshotNodeVotes(nodeID)
+}
+
+func (bot *Bot) sendVoters(user models.User, nodeID string) error {
+	votes, total, err := bot.nodeVotes(nodeID)
+	if err != nil {
+		return fmt.Errorf("nodeVotes: %s", err.Error())
+	}
+	snapshot, err := bot.dao.GetVotesSnapshots(filters.VotesSnapshots{NodeIDs: []string{nodeID}})
+	if err != nil {
+		return fmt.Errorf("dao.GetVotesSnapshots: %s", err.Error())
+	}
+	prev := make(map[string]decimal.Decimal)
+	prevTotal := decimal.Zero
+	var snapshotTime time.Time
+	for _, item := range snapshot {
+		prev[item.Address] = item.Value
+		prevTotal = prevTotal.Add(item.Value)
+		snapshotTime = item.CreatedAt
+	}
+
+	na := bot.dictionary.Get("t.not_available", user.Lang)
+	votersChange, totalChange, snapshotDate := na, na, na
+	if len(snapshot) != 0 {
+		votersChange = fmt.Sprintf("%+d", len(votes)-len(snapshot))
+		totalChange = signedDecimal(total.Sub(prevTotal).Truncate(4))
+		snapshotDate = snapshotTime.Format("2006-01-02 15:04")
+	}
+	lines := []string{fmt.Sprintf(
+		bot.dictionary.Get("t.voters", user.Lang),
+		nodeID,
+		len(votes),
+		votersChange,
+		total.Truncate(4).String(),
+		totalChange,
+		snapshotDate,
+	)}
+	for i, vote := range votes {
+		if i == topVotersCount {
+			break
+		}
+		share := decimal.Zero
+		if !total.IsZero() {
+			share = vote.Value.Div(total).Mul(hundred)
+		}
+		change := na
+		if len(snapshot) != 0 {
+			p, ok := prev[vote.Address]
+			if ok {
+				change = signedDecimal(vote.Value.Sub(p).Truncate(4))
+			} else {
+				change = bot.dictionary.Get("t.new_voter", user.Lang)
+			}
+		}
+		lines = append(lines, fmt.Sprintf(
+			bot.dictionary.Get("t.voters_item", user.Lang),
+			i+1,
+			vote.Address,
+			vote.Value.Truncate(4).String(),
+			share.StringFixed(2),
+			change,
+		))
+	}
+	return bot.sendText(user, strings.Join(lines, "\n"))
+}
+
+// voterShare returns the voter share (in percent) of the node votes before the vote was canceled.
+// The last snapshot is preferred, current votes are used when the voter is not in the snapshot.
+func (bot *Bot) voterShare(nodeID string, voter string, canceled decimal.Decimal) (decimal.Decimal, error) {
+	snapshot, err := bot.dao.GetVotesSnapshots(filters.VotesSnapshots{NodeIDs: []string{nodeID}})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("dao.GetVotesSnapshots: %s", err.Error())
+	}
+	value, total := decimal.Zero, decimal.Zero
+	for _, item := range snapshot {
+		if item.Address == voter {
+			value = item.Value
+		}
+		total = total.Add(item.Value)
+	}
+	if value.IsZero() {
+		votes, currentTotal, err := bot.nodeVotes(nodeID)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("nodeVotes: %s", err.Error())
+		}
+		value = canceled
+		for _, vote := range votes {
+			if vote.Address == voter {
+				value = value.Add(vote.Value)
+			}
+		}
+		total = currentTotal.Add(canceled)
+	}
+	if total.IsZero() {
+		return decimal.Zero, nil
+	}
+	return value.Div(total).Mul(hundred), nil
+}
+
+// notifyWhaleCancel alerts the node followers whose share threshold is exceeded by the voter which canceled the vote.
+func (bot *Bot) notifyWhaleCancel(users map[uint64]models.User, nodeID string, voter string, value decimal.Decimal) {
+	interested := false
+	for _, user := range users {
+		if !user.Mute && user.WhaleShare.IsPositive() {
+			interested = true
+			break
+		}
+	}
+	if !interested {
+		return
+	}
+	share, err := bot.voterShare(nodeID, voter, value)
+	if err != nil {
+		log.Error("Bot: notifyWhaleCancel: voterShare: %s", err.Error())
+		return
+	}
+	for _, user := range users {
+		if user.Mute || !user.WhaleShare.IsPositive() || share.LessThan(user.WhaleShare) {
+			continue
+		}
+		text := fmt.Sprintf(
+			bot.dictionary.Get("t.whale_cancel_vote", user.Lang),
+			voter,
+			share.StringFixed(2),
+			nodeID,
+			value.Truncate(4).String(),
+		)
+		err = bot.sendMsg(tgbotapi.NewMessage(user.TgID, text))
+		if err != nil {
+			log.Error("Bot: notifyWhaleCancel: api.Send: %s", err.Error())
+		}
+	}
+}
+
+func signedDecimal(d decimal.Decimal) string {
+	if d.IsNegative() {
+		return d.String()
+	}
+	return "+" + d.String()
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/dao/derrors"
+	"github.com/everstake/nebulas-tg-bot/dao/filters"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
+	"net"
+	"net/url"
+	"strings"
+)
+
+type webhookTarget struct {
+	address string
+	nodeID  string
+	url     string
+	secret  string
+}
+
+// sendWebhooks delivers the event to every callback registered for the given addresses.
+// Must not be called while bot.mu is held.
+func (bot *Bot) sendWebhooks(addresses []string, event webhook.Event) {
+	bot.sendNodeWebhooks("", addresses, event)
+}
+
+// sendNodeWebhooks delivers the event to the callbacks of the node follows and of the given addresses,
+// a callback registered for the node and for one of the addresses gets the event once.
+// Must not be called while bot.mu is held.
+func (bot *Bot) sendNodeWebhooks(nodeID string, addresses []string, event webhook.Event) {
+	var targets []webhookTarget
+	nodeTargets := make(map[uint64]string) // [userID]url
+	bot.mu.RLock()
+	if nodeID != "" {
+		for userID, u := range bot.nodeWebhooks[nodeID] {
+			user, ok := bot.users[userID]
+			if !ok || user.WebhookSecret == "" {
+				continue
+			}
+			nodeTargets[userID] = u
+			targets = append(targets, webhookTarget{
+				nodeID: nodeID,
+				url:    u,
+				secret: user.WebhookSecret,
+			})
+		}
+	}
+	for _, address := range getUniqStrings(addresses) {
+		for userID, u := range bot.webhookURLs[address] {
+			user, ok := bot.users[userID]
+			if !ok || user.WebhookSecret == "" || nodeTargets[userID] == u {
+				continue
+			}
+			targets = append(targets, webhookTarget{
+				address: address,
+				url:     u,
+				secret:  user.WebhookSecret,
+			})
+		}
+	}
+	bot.mu.RUnlock()
+	for _, t := range targets {
+		e := event
+		e.Address = t.address
+		e.NodeID = t.nodeID
+		bot.webhook.Send(t.url, t.secret, e)
+	}
+}
+
+// webhookSecret creates the user secret with the first callback.
+func (bot *Bot) webhookSecret(user models.User) (models.User, error) {
+	if user.WebhookSecret != "" {
+		return user, nil
+	}
+	var err error
+	user.WebhookSecret, err = webhook.NewSecret()
+	if err != nil {
+		return user, fmt.Errorf("webhook.NewSecret: %s", err.Error())
+	}
+	err = bot.dao.UpdateUser(user)
+	if err != nil {
+		return user, fmt.Errorf("dao.UpdateUser: %s", err.Error())
+	}
+	bot.updateUserSettings(user)
+	return user, nil
+}
+
+func (bot *Bot) saveWebhook(user models.User, address string, webhookURL string) (models.User, error) {
+	addressModel, ua, err := bot.findSubscription(user, address)
+	if err != nil {
+		return user, fmt.Errorf("findSubscription: %s", err.Error())
+	}
+	if webhookURL != "" {
+		user, err = bot.webhookSecret(user)
+		if err != nil {
+			return user, fmt.Errorf("webhookSecret: %s", err.Error())
+		}
+	}
+	ua.Webhook = webhookURL
+	err = bot.dao.UpdateUserAddress(ua)
+	if err != nil {
+		return user, fmt.Errorf("dao.UpdateUserAddress: %s", err.Error())
+	}
+	bot.setWebhook(user, addressModel, webhookURL)
+	return user, nil
+}
+
+// saveNodeWebhook sets the callback of the node follow, it gets the events of the node.
+func (bot *Bot) saveNodeWebhook(user models.User, nodeID string, webhookURL string) (models.User, error) {
+	usersNodes, err := bot.dao.GetUsersNodes(filters.UsersNodes{UserIDs: []uint64{user.ID}, NodeIDs: []string{nodeID}})
+	if err != nil {
+		return user, fmt.Errorf("dao.GetUsersNodes: %s", err.Error())
+	}
+	if len(usersNodes) == 0 {
+		return user, errors.New(derrors.ErrNotFound)
+	}
+	if webhookURL != "" {
+		user, err = bot.webhookSecret(user)
+		if err != nil {
+			return user, fmt.Errorf("webhookSecret: %s", err.Error())
+		}
+	}
+	un := usersNodes[0]
+	un.Webhook = webhookURL
+	err = bot.dao.UpdateUserNode(un)
+	if err != nil {
+		return user, fmt.Errorf("dao.UpdateUserNode: %s", err.Error())
+	}
+	bot.mu.Lock()
+	bot.setNodeWebhook(un)
+	bot.mu.Unlock()
+	return user, nil
+}
+
+// setNodeWebhook updates the callback of the node follow, mu must be held.
+func (bot *Bot) setNodeWebhook(un models.UserNode) {
+	if un.Webhook == "" {
+		delete(bot.nodeWebhooks[un.NodeID], un.UserID)
+		return
+	}
+	_, ok := bot.nodeWebhooks[un.NodeID]
+	if !ok {
+		bot.nodeWebhooks[un.NodeID] = make(map[uint64]string)
+	}
+	bot.nodeWebhooks[un.NodeID][un.UserID] = un.Webhook
+}
+
+// validWebhookURL accepts https URLs of public hosts, the resolved address is checked again on delivery.
+func validWebhookURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip == nil || webhook.PublicIP(ip)
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"github.com/everstake/nebulas-tg-bot/config"
+	"github.com/everstake/nebulas-tg-bot/models"
+	"github.com/everstake/nebulas-tg-bot/services/webhook"
+	"sort"
+	"testing"
+)
+
+type testWebhooks struct {
+	sent []string // url|address|node
+}
+
+func (w *testWebhooks) Send(url string, secret string, event webhook.Event) {
+	w.sent = append(w.sent, url+"|"+event.Address+"|"+event.NodeID)
+}
+
+func (w *testWebhooks) Run() {}
+
+func TestValidWebhookURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://example.com/hook", want: true},
+		{url: "https://8.8.8.8:8443/hook", want: true},
+		{url: "http://example.com/hook", want: false},
+		{url: "https://", want: false},
+		{url: "example.com", want: false},
+		{url: "https://localhost/hook", want: false},
+		{url: "https://api.localhost/hook", want: false},
+		{url: "https://127.0.0.1/hook", want: false},
+		{url: "https://[::1]/hook", want: false},
+		{url: "https://10.1.2.3/hook", want: false},
+		{url: "https://172.20.0.1/hook", want: false},
+		{url: "https://192.168.1.1/hook", want: false},
+		{url: "https://169.254.169.254/latest", want: false},
+		{url: "https://0.0.0.0/hook", want: false},
+		{url: "https://[fd00::1]/hook", want: false},
+	}
+	for _, test := range tests {
+		got := validWebhookURL(test.url)
+		if got != test.want {
+			t.Errorf("validWebhookURL(%q) = %t, want %t", test.url, got, test.want)
+		}
+	}
+}
+
+func TestSendNodeWebhooks(t *testing.T) {
+	const (
+		nodeID  = "node"
+		account = "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"
+	)
+	bot, _ := newTestBot(config.Config{})
+	hooks := &testWebhooks{}
+	bot.webhook = hooks
+	bot.users = map[uint64]models.User{
+		1: {ID: 1, WebhookSecret: "s1"}, // follows the node
+		2: {ID: 2, WebhookSecret: "s2"}, // follows the node and subscribes its account with the same URL
+		3: {ID: 3, WebhookSecret: "s3"}, // subscribes the account only
+		4: {ID: 4},                      // has no secret
+	}
+	bot.setNodeWebhook(models.UserNode{UserID: 1, NodeID: nodeID, Webhook: "https://one.io"})
+	bot.setNodeWebhook(models.UserNode{UserID: 2, NodeID: nodeID, Webhook: "https://two.io"})
+	bot.setNodeWebhook(models.UserNode{UserID: 4, NodeID: nodeID, Webhook: "https://four.io"})
+	bot.webhookURLs[account] = map[uint64]string{2: "https://two.io", 3: "https://three.io"}
+
+	bot.sendNodeWebhooks(nodeID, []string{account}, webhook.Event{Event: webhook.EventNodeStatus})
+	sort.Strings(hooks.sent)
+	want := []string{"https://one.io||node", "https://three.io|" + account + "|", "https://two.io||node"}
+	if len(hooks.sent) != len(want) {
+		t.Fatalf("sent %q, want %q", hooks.sent, want)
+	}
+	for i := range want {
+		if hooks.sent[i] != want[i] {
+			t.Errorf("sent %q, want %q", hooks.sent, want)
+		}
+	}
+
+	hooks.sent = nil
+	bot.setNodeWebhook(models.UserNode{UserID: 1, NodeID: nodeID})
+	bot.sendNodeWebhooks(nodeID, nil, webhook.Event{Event: webhook.EventNodeStatus})
+	if len(hooks.sent) != 1 || hooks.sent[0] != "https://two.io||node" {
+		t.Errorf("sent %q after the webhook was removed", hooks.sent)
+	}
+}
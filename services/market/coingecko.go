@@ -0,0 +1,50 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+type (
+	// coingecko returns the USDT rates, currencies are the coingecko vs_currencies codes.
+	coingecko              struct{}
+	coingeckoPriceResponse map[string]map[string]decimal.Decimal
+)
+
+func (ex *coingecko) GetRates(currencies []string) (rates map[string]decimal.Decimal, err error) {
+	url := fmt.Sprintf(
+		"https://api.coingecko.com/api/v3/simple/price?ids=tether&vs_currencies=%s",
+		strings.ToLower(strings.Join(currencies, ",")),
+	)
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return rates, fmt.Errorf("httpClient.Get: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return rates, fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return rates, fmt.Errorf("ioutil.ReadAll: %s", err.Error())
+	}
+	var prices coingeckoPriceResponse
+	err = json.Unmarshal(data, &prices)
+	if err != nil {
+		return rates, fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+	rates = make(map[string]decimal.Decimal)
+	for currency, rate := range prices["tether"] {
+		if rate.IsPositive() {
+			rates[strings.ToUpper(currency)] = rate
+		}
+	}
+	if len(rates) == 0 {
+		return rates, fmt.Errorf("invalid response")
+	}
+	return rates, nil
+}
@@ -9,17 +9,19 @@ import (
 )
 
 type (
-	gate struct{}
+	gate struct {
+		pair string
+	}
 	gateTickerResponse struct {
 		Last decimal.Decimal `json:"last"`
 	}
 )
 
 func (ex *gate) GetPrice() (price decimal.Decimal, err error) {
-	url := "https://data.gateio.la/api2/1/ticker/nax_usdt"
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("https://data.gateio.la/api2/1/ticker/%s", ex.pair)
+	resp, err := httpClient.Get(url)
 	if err != nil {
-		return price, fmt.Errorf("http.Get: %s", err.Error())
+		return price, fmt.Errorf("httpClient.Get: %s", err.Error())
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
@@ -40,4 +42,3 @@ func (ex *gate) GetPrice() (price decimal.Decimal, err error) {
 	}
 	return price, nil
 }
-
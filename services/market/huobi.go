@@ -0,0 +1,47 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"net/http"
+)
+
+type (
+	huobi struct {
+		symbol string
+	}
+	huobiTickerResponse struct {
+		Status string `json:"status"`
+		Tick   struct {
+			Close decimal.Decimal `json:"close"`
+		} `json:"tick"`
+	}
+)
+
+func (ex *huobi) GetPrice() (price decimal.Decimal, err error) {
+	url := fmt.Sprintf("https://api.huobi.pro/market/detail/merged?symbol=%s", ex.symbol)
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return price, fmt.Errorf("httpClient.Get: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return price, fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return price, fmt.Errorf("ioutil.ReadAll: %s", err.Error())
+	}
+	var ticker huobiTickerResponse
+	err = json.Unmarshal(data, &ticker)
+	if err != nil {
+		return price, fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+	price = ticker.Tick.Close
+	if ticker.Status != "ok" || price.IsZero() {
+		return price, fmt.Errorf("invalid response")
+	}
+	return price, nil
+}
@@ -3,86 +3,199 @@ package market
 import (
 	"github.com/everstake/nebulas-tg-bot/log"
 	"github.com/shopspring/decimal"
+	"net/http"
+	"sort"
 	"sync"
 	"time"
 )
 
+const (
+	AssetNAS = "NAS"
+	AssetNAX = "NAX"
+
+	// CurrencyUSD is the quote currency of the trackers, USDT is taken as USD.
+	CurrencyUSD = "USD"
+
+	updateInterval = time.Minute * 5
+	requestTimeout = time.Second * 15
+	// StaleAfter is the age after which a price should be labeled as stale.
+	StaleAfter = updateInterval * 3
+)
+
+// Currencies are the supported display currencies.
+var Currencies = []string{CurrencyUSD, "EUR", "GBP", "CNY", "JPY", "KRW", "RUB", "BTC", "ETH"}
+
+// maxDeviation is the max relative distance from the median, prices beyond it are rejected as outliers.
+var maxDeviation = decimal.New(1, -1)
+
+// httpClient is shared by the trackers, the timeout keeps one exchange from stalling the refresh of every asset.
+var httpClient = &http.Client{Timeout: requestTimeout}
+
+// minOutlierPrices is the min number of prices to reject outliers, with two prices the median is their mean.
+const minOutlierPrices = 3
+
 type (
 	Market struct {
-		priceNAS   decimal.Decimal
-		priceNAX   decimal.Decimal
-		trackerNAS Tracker
-		trackerNAX Tracker
-		mu         *sync.Mutex
+		prices    map[string]decimal.Decimal // [asset]
+		updated   map[string]time.Time       // [asset]
+		trackers  map[string][]Tracker       // [asset]
+		rates     map[string]decimal.Decimal // [currency] per USDT
+		fx        FXTracker
+		listeners []Listener
+		mu        *sync.Mutex
 	}
 	Tracker interface {
 		GetPrice() (price decimal.Decimal, err error)
 	}
+	// FXTracker returns the prices of one USDT in the currencies.
+	FXTracker interface {
+		GetRates(currencies []string) (rates map[string]decimal.Decimal, err error)
+	}
+	// Listener is called after every price refresh.
+	Listener func()
 )
 
+// NewMarket does not wait for the prices, they are zero until the first refresh in Run.
 func NewMarket() *Market {
-	priceNAS := decimal.Zero
-	trackerNAS := okex{}
-	var err error
+	return &Market{
+		prices:  make(map[string]decimal.Decimal),
+		updated: make(map[string]time.Time),
+		trackers: map[string][]Tracker{
+			AssetNAS: {
+				&okex{instrument: "NAS-USDT"},
+				&gate{pair: "nas_usdt"},
+				&huobi{symbol: "nasusdt"},
+			},
+			// NAX is listed on two exchanges only, so its outliers are never rejected and its price is their mean
+			AssetNAX: {
+				&gate{pair: "nax_usdt"},
+				&mxc{symbol: "NAX_USDT"},
+			},
+		},
+		rates: map[string]decimal.Decimal{CurrencyUSD: decimal.New(1, 0)},
+		fx:    &coingecko{},
+		mu:    &sync.Mutex{},
+	}
+}
+
+func (m *Market) Run() {
 	for {
-		priceNAS, err = trackerNAS.GetPrice()
-		if err != nil {
-			log.Error("Market: tracker.GetPrice(nas): %s", err.Error())
-			<-time.After(time.Second * 5)
-		} else {
-			break
+		m.refresh()
+		m.mu.Lock()
+		listeners := m.listeners
+		m.mu.Unlock()
+		for _, listener := range listeners {
+			listener()
 		}
+		<-time.After(updateInterval)
 	}
-	priceNAX := decimal.Zero
-	trackerNAX := gate{}
-	for {
-		priceNAX, err = trackerNAX.GetPrice()
-		if err != nil {
-			log.Error("Market: tracker.GetPrice(nax): %s", err.Error())
-			<-time.After(time.Second * 5)
-		} else {
-			break
+}
+
+func (m *Market) refresh() {
+	m.mu.Lock()
+	trackers := make(map[string][]Tracker, len(m.trackers))
+	for asset, items := range m.trackers {
+		trackers[asset] = items
+	}
+	m.mu.Unlock()
+	for asset, items := range trackers {
+		var prices []decimal.Decimal
+		for _, tracker := range items {
+			price, err := tracker.GetPrice()
+			if err != nil {
+				log.Warn("Market: tracker.GetPrice(%s): %s", asset, err.Error())
+				continue
+			}
+			prices = append(prices, price)
+		}
+		if len(prices) == 0 {
+			log.Error("Market: no price for %s", asset)
+			continue
 		}
+		m.mu.Lock()
+		m.prices[asset] = aggregate(prices)
+		m.updated[asset] = time.Now()
+		m.mu.Unlock()
 	}
-	return &Market{
-		priceNAS:   priceNAS,
-		priceNAX:   priceNAX,
-		trackerNAS: &trackerNAS,
-		trackerNAX: &trackerNAX,
-		mu:         &sync.Mutex{},
+	rates, err := m.fx.GetRates(Currencies[1:])
+	if err != nil {
+		log.Warn("Market: fx.GetRates: %s", err.Error())
+		return
+	}
+	m.mu.Lock()
+	for currency, rate := range rates {
+		m.rates[currency] = rate
 	}
+	m.mu.Unlock()
 }
 
-func (m *Market) Run() {
-	for {
-		<-time.After(time.Minute * 5)
-		nas, err := m.trackerNAS.GetPrice()
-		if err != nil {
-			log.Error("Market: tracker.GetPrice(nas): %s", err.Error())
-		} else {
-			m.mu.Lock()
-			m.priceNAS = nas
-			m.mu.Unlock()
-		}
-		nax, err := m.trackerNAX.GetPrice()
-		if err != nil {
-			log.Error("Market: tracker.GetPrice(nax): %s", err.Error())
-		} else {
-			m.mu.Lock()
-			m.priceNAX = nax
-			m.mu.Unlock()
+// aggregate returns the median of the prices that are within maxDeviation of the median of all prices,
+// the outliers are kept when there are less than minOutlierPrices prices.
+func aggregate(prices []decimal.Decimal) decimal.Decimal {
+	m := median(prices)
+	if len(prices) < minOutlierPrices {
+		return m
+	}
+	var valid []decimal.Decimal
+	for _, price := range prices {
+		if price.Sub(m).Abs().LessThanOrEqual(m.Mul(maxDeviation)) {
+			valid = append(valid, price)
 		}
 	}
+	if len(valid) == 0 {
+		return m
+	}
+	return median(valid)
+}
+
+func median(prices []decimal.Decimal) decimal.Decimal {
+	sorted := make([]decimal.Decimal, len(prices))
+	copy(sorted, prices)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].LessThan(sorted[j])
+	})
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return sorted[n/2-1].Add(sorted[n/2]).Div(decimal.New(2, 0))
+}
+
+// OnUpdate registers a listener of the price refreshes, it should be called before Run.
+func (m *Market) OnUpdate(listener Listener) {
+	m.mu.Lock()
+	m.listeners = append(m.listeners, listener)
+	m.mu.Unlock()
 }
 
 func (m *Market) GetNASPrice() decimal.Decimal {
+	return m.GetPrice(AssetNAS)
+}
+
+func (m *Market) GetNAXPrice() decimal.Decimal {
+	return m.GetPrice(AssetNAX)
+}
+
+func (m *Market) GetPrice(asset string) decimal.Decimal {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.priceNAS.Add(decimal.Zero)
+	return m.prices[asset].Add(decimal.Zero)
 }
 
-func (m *Market) GetNAXPrice() decimal.Decimal {
+// Convert converts the USDT value to the currency, ok is false while the rate is unknown.
+func (m *Market) Convert(value decimal.Decimal, currency string) (result decimal.Decimal, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rate, ok := m.rates[currency]
+	if !ok {
+		return result, false
+	}
+	return value.Mul(rate), true
+}
+
+// LastUpdated returns the time of the last successful refresh of the asset price, zero if there was none.
+func (m *Market) LastUpdated(asset string) time.Time {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.priceNAX.Add(decimal.Zero)
+	return m.updated[asset]
 }
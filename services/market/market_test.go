@@ -0,0 +1,57 @@
+package market
+
+import (
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+func decimals(values ...string) []decimal.Decimal {
+	items := make([]decimal.Decimal, len(values))
+	for i, value := range values {
+		items[i] = decimal.RequireFromString(value)
+	}
+	return items
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []decimal.Decimal
+		want   string
+	}{
+		{name: "single", prices: decimals("1.5"), want: "1.5"},
+		{name: "odd", prices: decimals("3", "1", "2"), want: "2"},
+		{name: "even", prices: decimals("4", "1", "3", "2"), want: "2.5"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := median(test.prices)
+			if !got.Equal(decimal.RequireFromString(test.want)) {
+				t.Errorf("median = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []decimal.Decimal
+		want   string
+	}{
+		{name: "single", prices: decimals("0.5"), want: "0.5"},
+		{name: "two prices are averaged", prices: decimals("1", "2"), want: "1.5"},
+		{name: "close prices", prices: decimals("1", "1.02", "0.98"), want: "1"},
+		{name: "outlier rejected", prices: decimals("1", "1.04", "2"), want: "1.02"},
+		{name: "outliers on both sides", prices: decimals("0.1", "1", "1.01", "0.99", "5"), want: "1"},
+		{name: "all spread", prices: decimals("1", "2", "4"), want: "2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := aggregate(test.prices)
+			if !got.Equal(decimal.RequireFromString(test.want)) {
+				t.Errorf("aggregate = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
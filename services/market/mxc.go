@@ -0,0 +1,46 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"net/http"
+)
+
+type (
+	mxc struct {
+		symbol string
+	}
+	mxcTickerResponse struct {
+		Code int `json:"code"`
+		Data []struct {
+			Last decimal.Decimal `json:"last"`
+		} `json:"data"`
+	}
+)
+
+func (ex *mxc) GetPrice() (price decimal.Decimal, err error) {
+	url := fmt.Sprintf("https://www.mxc.com/open/api/v2/market/ticker?symbol=%s", ex.symbol)
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return price, fmt.Errorf("httpClient.Get: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return price, fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return price, fmt.Errorf("ioutil.ReadAll: %s", err.Error())
+	}
+	var ticker mxcTickerResponse
+	err = json.Unmarshal(data, &ticker)
+	if err != nil {
+		return price, fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+	if ticker.Code != http.StatusOK || len(ticker.Data) == 0 || ticker.Data[0].Last.IsZero() {
+		return price, fmt.Errorf("invalid response")
+	}
+	return ticker.Data[0].Last, nil
+}
@@ -9,17 +9,19 @@ import (
 )
 
 type (
-	okex struct{}
+	okex struct {
+		instrument string
+	}
 	okexTickerResponse struct {
 		Last decimal.Decimal `json:"last"`
 	}
 )
 
 func (ex *okex) GetPrice() (price decimal.Decimal, err error) {
-	url := "https://www.okex.com/api/spot/v3/instruments/NAS-USDT/ticker"
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("https://www.okex.com/api/spot/v3/instruments/%s/ticker", ex.instrument)
+	resp, err := httpClient.Get(url)
 	if err != nil {
-		return price, fmt.Errorf("http.Get: %s", err.Error())
+		return price, fmt.Errorf("httpClient.Get: %s", err.Error())
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
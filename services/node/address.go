@@ -0,0 +1,138 @@
+package node
+
+import (
+	"bytes"
+	"golang.org/x/crypto/sha3"
+	"math/big"
+)
+
+// Nebulas address layout: padding (1 byte) + type (1 byte) + account hash (20 bytes) + checksum (4 bytes),
+// where checksum is the first 4 bytes of SHA3-256 of the preceding 22 bytes. It is base58 encoded into 35 chars.
+const (
+	AddressLength       = 26
+	AddressBase58Length = 35
+
+	AddressTypeNormal   byte = 0x57
+	AddressTypeContract byte = 0x58
+
+	addressPadding     byte = 0x19
+	addressTypeIndex        = 1
+	addressChecksumEnd      = AddressLength - addressChecksumLen
+	addressChecksumLen      = 4
+
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+type AddressError string
+
+func (e AddressError) Error() string {
+	return string(e)
+}
+
+// IsAddressError reports whether the address was rejected by ParseAddress.
+func IsAddressError(err error) bool {
+	_, ok := err.(AddressError)
+	return ok
+}
+
+const (
+	ErrAddressLength   AddressError = "invalid address length"
+	ErrAddressEncoding AddressError = "address contains non base58 characters"
+	ErrAddressPadding  AddressError = "invalid address prefix"
+	ErrAddressType     AddressError = "unknown address type"
+	ErrAddressChecksum AddressError = "invalid address checksum"
+)
+
+var base58Indexes = func() (indexes [256]int) {
+	for i := range indexes {
+		indexes[i] = -1
+	}
+	for i, c := range base58Alphabet {
+		indexes[c] = i
+	}
+	return indexes
+}()
+
+type Address []byte
+
+// ParseAddress decodes and validates the base58 representation of the address.
+func ParseAddress(s string) (Address, error) {
+	if len(s) != AddressBase58Length {
+		return nil, ErrAddressLength
+	}
+	data, ok := base58Decode(s)
+	if !ok {
+		return nil, ErrAddressEncoding
+	}
+	if len(data) != AddressLength {
+		return nil, ErrAddressLength
+	}
+	if data[0] != addressPadding {
+		return nil, ErrAddressPadding
+	}
+	addressType := data[addressTypeIndex]
+	if addressType != AddressTypeNormal && addressType != AddressTypeContract {
+		return nil, ErrAddressType
+	}
+	if !bytes.Equal(addressChecksum(data[:addressChecksumEnd]), data[addressChecksumEnd:]) {
+		return nil, ErrAddressChecksum
+	}
+	return Address(data), nil
+}
+
+func (a Address) Type() byte {
+	return a[addressTypeIndex]
+}
+
+func (a Address) IsContract() bool {
+	return a.Type() == AddressTypeContract
+}
+
+func (a Address) String() string {
+	return base58Encode(a)
+}
+
+func addressChecksum(content []byte) []byte {
+	h := sha3.Sum256(content)
+	return h[:addressChecksumLen]
+}
+
+func base58Decode(s string) ([]byte, bool) {
+	n := new(big.Int)
+	radix := big.NewInt(int64(len(base58Alphabet)))
+	for i := 0; i < len(s); i++ {
+		idx := base58Indexes[s[i]]
+		if idx < 0 {
+			return nil, false
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+	data := n.Bytes()
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), data...), true
+}
+
+func base58Encode(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(int64(len(base58Alphabet)))
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
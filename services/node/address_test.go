@@ -0,0 +1,50 @@
+package node
+
+import (
+	"testing"
+)
+
+// encodeTestAddress builds the base58 address with a valid checksum from the padding, type and the 20 bytes hash.
+func encodeTestAddress(padding byte, addressType byte) string {
+	data := append([]byte{padding, addressType}, make([]byte, 20)...)
+	data = append(data, addressChecksum(data)...)
+	return base58Encode(data)
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		err      error
+		contract bool
+	}{
+		{name: "account", address: "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"},
+		{name: "contract", address: NAXContract, contract: true},
+		{name: "short", address: "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkM", err: ErrAddressLength},
+		{name: "empty", address: "", err: ErrAddressLength},
+		{name: "not base58", address: "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXk0R", err: ErrAddressEncoding},
+		{name: "checksum", address: "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMS", err: ErrAddressChecksum},
+		{name: "type", address: encodeTestAddress(addressPadding, 0x01), err: ErrAddressType},
+		{name: "padding", address: encodeTestAddress(addressPadding+1, AddressTypeNormal), err: ErrAddressPadding},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			address, err := ParseAddress(test.address)
+			if err != test.err {
+				t.Fatalf("ParseAddress(%q) error = %v, want %v", test.address, err, test.err)
+			}
+			if err != nil {
+				if !IsAddressError(err) {
+					t.Errorf("IsAddressError(%v) = false", err)
+				}
+				return
+			}
+			if address.IsContract() != test.contract {
+				t.Errorf("IsContract() = %t, want %t", address.IsContract(), test.contract)
+			}
+			if address.String() != test.address {
+				t.Errorf("String() = %s, want %s", address.String(), test.address)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package node
+
+// DPoS constants of the Nebulas consensus.
+const (
+	BlockInterval   = 15                  // seconds
+	DynastyInterval = 210 * BlockInterval // seconds
+	DynastySize     = 21
+)
+
+// ProposerAt returns the miner expected to propose the block at the timestamp (in seconds),
+// an empty string is returned when the timestamp is not a block slot.
+func ProposerAt(timestamp int64, miners []string) string {
+	offset := timestamp % DynastyInterval
+	if offset%BlockInterval != 0 || len(miners) == 0 {
+		return ""
+	}
+	offset = offset / BlockInterval % DynastySize
+	if int(offset) >= len(miners) {
+		return ""
+	}
+	return miners[offset]
+}
@@ -0,0 +1,35 @@
+package node
+
+import (
+	"testing"
+)
+
+func TestProposerAt(t *testing.T) {
+	miners := make([]string, DynastySize)
+	for i := range miners {
+		miners[i] = string(rune('a' + i))
+	}
+	tests := []struct {
+		name      string
+		timestamp int64
+		miners    []string
+		want      string
+	}{
+		{name: "first slot", timestamp: 0, miners: miners, want: "a"},
+		{name: "second slot", timestamp: BlockInterval, miners: miners, want: "b"},
+		{name: "last slot of the round", timestamp: (DynastySize - 1) * BlockInterval, miners: miners, want: "u"},
+		{name: "next round", timestamp: DynastySize * BlockInterval, miners: miners, want: "a"},
+		{name: "next dynasty", timestamp: DynastyInterval + 2*BlockInterval, miners: miners, want: "c"},
+		{name: "not a slot", timestamp: BlockInterval + 1, miners: miners, want: ""},
+		{name: "no miners", timestamp: 0, miners: nil, want: ""},
+		{name: "short dynasty", timestamp: 3 * BlockInterval, miners: miners[:3], want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ProposerAt(test.timestamp, test.miners)
+			if got != test.want {
+				t.Errorf("ProposerAt(%d) = %q, want %q", test.timestamp, got, test.want)
+			}
+		})
+	}
+}
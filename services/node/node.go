@@ -11,7 +11,7 @@ import (
 
 const PrecisionNAS = 18
 const PrecisionNAX = 9
-const naxContract = "n1etmdwczuAUCnMMvpGasfi8kwUbb2ddvRJ"
+const NAXContract = "n1etmdwczuAUCnMMvpGasfi8kwUbb2ddvRJ"
 const stakingContract = "n214bLrE3nREcpRewHXF7qRDWCcaxRSiUdw"
 const someAddress = "n1Jkdiq1H1HSXYJXtvDDkYm84Tmapo4hhMv"
 
@@ -51,6 +51,20 @@ type (
 			Transactions []Transaction `json:"transactions"`
 		} `json:"result"`
 	}
+	NebState struct {
+		Result struct {
+			ChainID uint64 `json:"chain_id"`
+			Tail    string `json:"tail"`
+			LIB     string `json:"lib"`
+			Height  uint64 `json:"height,string"`
+			Synced  bool   `json:"synchronized"`
+		} `json:"result"`
+	}
+	Dynasty struct {
+		Result struct {
+			Miners []string `json:"miners"`
+		} `json:"result"`
+	}
 	Transaction struct {
 		Hash            string          `json:"hash"`
 		ChainID         uint64          `json:"chain_id,string"`
@@ -116,6 +130,15 @@ type (
 			Result string `json:"result"`
 		} `json:"result"`
 	}
+	Events struct {
+		Result struct {
+			Events []Event `json:"events"`
+		} `json:"result"`
+	}
+	Event struct {
+		Topic string `json:"topic"`
+		Data  string `json:"data"`
+	}
 )
 
 func NewAPI(url string) *API {
@@ -139,6 +162,7 @@ func (api *API) post(endpoint string, params interface{}, data interface{}) erro
 	if err != nil {
 		return fmt.Errorf("client.Do: %s", err.Error())
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("bad status code: %d", resp.StatusCode)
 	}
@@ -163,6 +187,7 @@ func (api *API) get(endpoint string, data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("client.Do: %s", err.Error())
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("bad status code: %d", resp.StatusCode)
 	}
@@ -190,6 +215,25 @@ func (api *API) GetBlock(height uint64) (block Block, err error) {
 	return block, err
 }
 
+// GetNebState returns the node state, Height is the height of the tail block.
+func (api *API) GetNebState() (state NebState, err error) {
+	err = api.get("v1/user/nebstate", &state)
+	return state, err
+}
+
+// GetDynasty returns the miners of the dynasty the block belongs to.
+func (api *API) GetDynasty(height uint64) (dynasty Dynasty, err error) {
+	err = api.post("v1/user/dynasty", map[string]interface{}{"height": height}, &dynasty)
+	return dynasty, err
+}
+
+// GetEventsByHash returns the events emitted by the transaction, the list ends with the chain.transactionResult event.
+func (api *API) GetEventsByHash(hash string) (events []Event, err error) {
+	var result Events
+	err = api.post("v1/user/getEventsByHash", map[string]interface{}{"hash": hash}, &result)
+	return result.Result.Events, err
+}
+
 func (api *API) GetLatestIrreversibleBlock() (block Block, err error) {
 	err = api.get("v1/user/lib", &block)
 	return block, err
@@ -201,7 +245,7 @@ func (api *API) GetNAXBalance(address string) (result decimal.Decimal, err error
 		Function: "balanceOf",
 		Args:     string(args),
 	}
-	err = api.callContract(naxContract, contract, &result)
+	err = api.callContract(NAXContract, contract, &result)
 	return result, err
 }
 
@@ -234,7 +278,7 @@ func (api *API) GetNodesList() (list []ValidatorNode, err error) {
 		},
 		&list,
 	)
-	return list, nil
+	return list, err
 }
 
 func (api *API) GetNodeVotesList(nodeID string) (list []Vote, err error) {
@@ -0,0 +1,65 @@
+package node
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// Types of the transactions.
+const (
+	TxTypeBinary = "binary"
+	TxTypeCall   = "call"
+	TxTypeDeploy = "deploy"
+)
+
+type (
+	// Payload is the decoded data of a call or deploy transaction.
+	Payload struct {
+		Function   string
+		Args       []string
+		SourceType string
+		SourceSize int
+	}
+	deployPayload struct {
+		SourceType string `json:"SourceType"`
+		Source     string `json:"Source"`
+		Args       string `json:"Args"`
+	}
+)
+
+// DecodePayload decodes the base64 data of call and deploy transactions.
+func DecodePayload(tx Transaction) (payload Payload, err error) {
+	raw, err := base64.StdEncoding.DecodeString(tx.Data)
+	if err != nil {
+		return payload, fmt.Errorf("base64.DecodeString: %s", err.Error())
+	}
+	switch tx.Type {
+	case TxTypeCall:
+		var contract CallContract
+		err = json.Unmarshal(raw, &contract)
+		if err != nil {
+			return payload, fmt.Errorf("json.Unmarshal: %s", err.Error())
+		}
+		payload.Function = contract.Function
+		payload.Args = parseArgs(contract.Args)
+	case TxTypeDeploy:
+		var deploy deployPayload
+		err = json.Unmarshal(raw, &deploy)
+		if err != nil {
+			return payload, fmt.Errorf("json.Unmarshal: %s", err.Error())
+		}
+		payload.SourceType = deploy.SourceType
+		payload.SourceSize = len(deploy.Source)
+		payload.Args = parseArgs(deploy.Args)
+	default:
+		return payload, fmt.Errorf("unsupported type %s", tx.Type)
+	}
+	return payload, nil
+}
+
+// Fee returns the paid fee in NAS.
+func (tx Transaction) Fee() decimal.Decimal {
+	return tx.GasUsed.Mul(tx.GasPrice).Div(PrecisionDivNAS)
+}
@@ -0,0 +1,138 @@
+package node
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// Kinds of the staking contract calls.
+const (
+	StakingVote          = "vote"
+	StakingCancelVote    = "cancel_vote"
+	StakingTransfer      = "transfer"
+	StakingRegister      = "register"
+	StakingUnregister    = "unregister"
+	StakingUpdateInfo    = "update_info"
+	StakingManagerChange = "manager_change"
+	StakingStake         = "stake"
+	StakingUnstake       = "unstake"
+	StakingUnknown       = "unknown"
+)
+
+const (
+	argNodeID = iota
+	argAddress
+	argValue
+)
+
+type (
+	stakingFunction struct {
+		kind  string
+		token string // token of the value argument
+		role  string // node account changed by the call
+		args  []int
+	}
+	// StakingCall is a decoded call of the staking contract, missing arguments are left empty.
+	StakingCall struct {
+		Function string
+		Kind     string
+		NodeID   string
+		Address  string
+		Role     string
+		Token    string
+		Value    decimal.Decimal // in the smallest units of Token
+		Args     []string
+	}
+)
+
+var stakingFunctions = map[string]stakingFunction{
+	"vote":                   {kind: StakingVote, token: "NAX", args: []int{argNodeID, argValue}},
+	"cancelVote":             {kind: StakingCancelVote, token: "NAX", args: []int{argNodeID, argValue}},
+	"transfer":               {kind: StakingTransfer, token: "NAX", args: []int{argAddress, argValue}},
+	"registerNode":           {kind: StakingRegister, args: []int{argNodeID}},
+	"register":               {kind: StakingRegister, args: []int{argNodeID}},
+	"unregisterNode":         {kind: StakingUnregister, args: []int{argNodeID}},
+	"unregister":             {kind: StakingUnregister, args: []int{argNodeID}},
+	"updateNodeInfo":         {kind: StakingUpdateInfo, args: []int{argNodeID}},
+	"changeConsensusManager": {kind: StakingManagerChange, role: "consensus_manager", args: []int{argNodeID, argAddress}},
+	"changeGovManager":       {kind: StakingManagerChange, role: "gov_manager", args: []int{argNodeID, argAddress}},
+	"changeStakingAccount":   {kind: StakingManagerChange, role: "staking_account", args: []int{argNodeID, argAddress}},
+	"changeRegistrant":       {kind: StakingManagerChange, role: "registrant", args: []int{argNodeID, argAddress}},
+	"stake":                  {kind: StakingStake, token: "NAS", args: []int{argNodeID, argValue}},
+	"pledge":                 {kind: StakingStake, token: "NAS", args: []int{argNodeID, argValue}},
+	"unstake":                {kind: StakingUnstake, token: "NAS", args: []int{argNodeID, argValue}},
+	"cancelPledge":           {kind: StakingUnstake, token: "NAS", args: []int{argNodeID, argValue}},
+}
+
+// DecodeStakingCall decodes the base64 data of a call transaction. Arguments may be strings, numbers
+// or objects, an argument which can't be used for its field leaves the field empty.
+func DecodeStakingCall(data string) (call StakingCall, err error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return call, fmt.Errorf("base64.DecodeString: %s", err.Error())
+	}
+	var contract CallContract
+	err = json.Unmarshal(raw, &contract)
+	if err != nil {
+		return call, fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+	call.Function = contract.Function
+	call.Args = parseArgs(contract.Args)
+	fn, ok := stakingFunctions[contract.Function]
+	if !ok {
+		call.Kind = StakingUnknown
+		return call, nil
+	}
+	call.Kind = fn.kind
+	call.Token = fn.token
+	call.Role = fn.role
+	for i, arg := range fn.args {
+		if i >= len(call.Args) {
+			break
+		}
+		switch arg {
+		case argNodeID:
+			call.NodeID = call.Args[i]
+		case argAddress:
+			call.Address = call.Args[i]
+		case argValue:
+			call.Value, _ = decimal.NewFromString(call.Args[i])
+		}
+	}
+	return call, nil
+}
+
+// Amount returns the value in whole tokens.
+func (call StakingCall) Amount() decimal.Decimal {
+	if call.Token == "NAS" {
+		return call.Value.Div(PrecisionDivNAS)
+	}
+	return call.Value.Div(PrecisionDivNAX)
+}
+
+// parseArgs converts the JSON array of arguments to strings, objects are kept as compact JSON.
+func parseArgs(args string) []string {
+	var items []json.RawMessage
+	err := json.Unmarshal([]byte(args), &items)
+	if err != nil {
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		var s string
+		if json.Unmarshal(item, &s) == nil {
+			result = append(result, s)
+			continue
+		}
+		var buf bytes.Buffer
+		if json.Compact(&buf, item) == nil {
+			result = append(result, buf.String())
+			continue
+		}
+		result = append(result, string(item))
+	}
+	return result
+}
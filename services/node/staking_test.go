@@ -0,0 +1,137 @@
+package node
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"reflect"
+	"testing"
+)
+
+func encodeTestCall(function string, args string) string {
+	data, _ := json.Marshal(CallContract{Function: function, Args: args})
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestDecodeStakingCall(t *testing.T) {
+	const (
+		nodeID  = "node-1"
+		account = "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"
+	)
+	tests := []struct {
+		function string
+		args     string
+		want     StakingCall
+	}{
+		{
+			function: "vote",
+			args:     `["node-1", "1500000000000000000"]`,
+			want:     StakingCall{Kind: StakingVote, NodeID: nodeID, Token: "NAX", Value: decimal.RequireFromString("1500000000000000000")},
+		},
+		{
+			function: "cancelVote",
+			args:     `["node-1", 200]`,
+			want:     StakingCall{Kind: StakingCancelVote, NodeID: nodeID, Token: "NAX", Value: decimal.RequireFromString("200")},
+		},
+		{
+			function: "transfer",
+			args:     `["n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR", "10"]`,
+			want:     StakingCall{Kind: StakingTransfer, Address: account, Token: "NAX", Value: decimal.RequireFromString("10")},
+		},
+		{function: "registerNode", args: `["node-1"]`, want: StakingCall{Kind: StakingRegister, NodeID: nodeID}},
+		{function: "register", args: `["node-1"]`, want: StakingCall{Kind: StakingRegister, NodeID: nodeID}},
+		{function: "unregisterNode", args: `["node-1"]`, want: StakingCall{Kind: StakingUnregister, NodeID: nodeID}},
+		{function: "unregister", args: `["node-1"]`, want: StakingCall{Kind: StakingUnregister, NodeID: nodeID}},
+		{
+			function: "updateNodeInfo",
+			args:     `["node-1", {"name": "Node", "url": "https://node.io"}]`,
+			want:     StakingCall{Kind: StakingUpdateInfo, NodeID: nodeID},
+		},
+		{
+			function: "changeConsensusManager",
+			args:     `["node-1", "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"]`,
+			want:     StakingCall{Kind: StakingManagerChange, NodeID: nodeID, Address: account, Role: "consensus_manager"},
+		},
+		{
+			function: "changeGovManager",
+			args:     `["node-1", "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"]`,
+			want:     StakingCall{Kind: StakingManagerChange, NodeID: nodeID, Address: account, Role: "gov_manager"},
+		},
+		{
+			function: "changeStakingAccount",
+			args:     `["node-1", "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"]`,
+			want:     StakingCall{Kind: StakingManagerChange, NodeID: nodeID, Address: account, Role: "staking_account"},
+		},
+		{
+			function: "changeRegistrant",
+			args:     `["node-1", "n1JNHZJEUvfBYfjDRD14Q73FX62nJAzXkMR"]`,
+			want:     StakingCall{Kind: StakingManagerChange, NodeID: nodeID, Address: account, Role: "registrant"},
+		},
+		{
+			function: "stake",
+			args:     `["node-1", "20000000000000000000000"]`,
+			want:     StakingCall{Kind: StakingStake, NodeID: nodeID, Token: "NAS", Value: decimal.RequireFromString("20000000000000000000000")},
+		},
+		{
+			function: "pledge",
+			args:     `["node-1", "5"]`,
+			want:     StakingCall{Kind: StakingStake, NodeID: nodeID, Token: "NAS", Value: decimal.RequireFromString("5")},
+		},
+		{
+			function: "unstake",
+			args:     `["node-1", "5"]`,
+			want:     StakingCall{Kind: StakingUnstake, NodeID: nodeID, Token: "NAS", Value: decimal.RequireFromString("5")},
+		},
+		{
+			function: "cancelPledge",
+			args:     `["node-1", "5"]`,
+			want:     StakingCall{Kind: StakingUnstake, NodeID: nodeID, Token: "NAS", Value: decimal.RequireFromString("5")},
+		},
+		{function: "claim", args: `["node-1"]`, want: StakingCall{Kind: StakingUnknown}},
+		{function: "vote", args: `["node-1"]`, want: StakingCall{Kind: StakingVote, NodeID: nodeID, Token: "NAX"}},
+		{function: "vote", args: `not json`, want: StakingCall{Kind: StakingVote, Token: "NAX"}},
+	}
+	for _, test := range tests {
+		t.Run(test.function+test.args, func(t *testing.T) {
+			got, err := DecodeStakingCall(encodeTestCall(test.function, test.args))
+			if err != nil {
+				t.Fatalf("DecodeStakingCall: %s", err.Error())
+			}
+			test.want.Function = test.function
+			test.want.Args = parseArgs(test.args)
+			if !got.Value.Equal(test.want.Value) {
+				t.Errorf("Value = %s, want %s", got.Value, test.want.Value)
+			}
+			got.Value = test.want.Value
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DecodeStakingCall = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeStakingCallErrors(t *testing.T) {
+	for _, data := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("{"))} {
+		_, err := DecodeStakingCall(data)
+		if err == nil {
+			t.Errorf("DecodeStakingCall(%q) error = nil", data)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args string
+		want []string
+	}{
+		{args: `["a", 1, true, {"b": [1, 2]}]`, want: []string{"a", "1", "true", `{"b":[1,2]}`}},
+		{args: `[]`, want: []string{}},
+		{args: ``, want: nil},
+	}
+	for _, test := range tests {
+		got := parseArgs(test.args)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseArgs(%q) = %q, want %q", test.args, got, test.want)
+		}
+	}
+}
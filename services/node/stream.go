@@ -0,0 +1,140 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Topics of the node subscription endpoint.
+const (
+	TopicLinkBlock               = "chain.linkBlock"
+	TopicLatestIrreversibleBlock = "chain.latestIrreversibleBlock"
+	TopicPendingTransaction      = "chain.pendingTransaction"
+	TopicTransactionResult       = "chain.transactionResult"
+)
+
+const (
+	streamBufferSize  = 100
+	maxStreamLineSize = 16 << 20
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+	// streamIdleTimeout drops a half-open connection, the irreversible block topic fires every block (~15s)
+	streamIdleTimeout = time.Minute
+)
+
+type (
+	// Stream consumes the v1/user/subscribe endpoint and reconnects when the connection drops.
+	Stream struct {
+		url       string
+		topics    []string
+		client    *http.Client
+		events    chan StreamEvent
+		connected int32
+	}
+	StreamEvent struct {
+		Topic string `json:"topic"`
+		Data  string `json:"data"`
+	}
+	streamMessage struct {
+		Result StreamEvent `json:"result"`
+		Error  string      `json:"error"`
+	}
+)
+
+func (api *API) Subscribe(topics ...string) *Stream {
+	return &Stream{
+		url:    fmt.Sprintf("%s/v1/user/subscribe", api.url),
+		topics: topics,
+		client: &http.Client{}, // no timeout, the response never ends
+		events: make(chan StreamEvent, streamBufferSize),
+	}
+}
+
+// Events returns the channel of received events, events are dropped while the channel is full.
+func (s *Stream) Events() <-chan StreamEvent {
+	return s.events
+}
+
+// Connected reports whether the stream is currently receiving events.
+func (s *Stream) Connected() bool {
+	return atomic.LoadInt32(&s.connected) == 1
+}
+
+// Run keeps the subscription alive, it never returns.
+func (s *Stream) Run() {
+	delay := minReconnectDelay
+	for {
+		received, err := s.consume()
+		if err != nil {
+			log.Warn("Node: Stream: %s", err.Error())
+		}
+		if received {
+			delay = minReconnectDelay
+		}
+		<-time.After(delay)
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// consume reads events until the connection drops, received is true when at least one event came.
+func (s *Stream) consume() (received bool, err error) {
+	body, _ := json.Marshal(map[string]interface{}{"topics": s.topics})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(streamIdleTimeout, cancel)
+	defer idle.Stop()
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewBuffer(body))
+	if err != nil {
+		return false, fmt.Errorf("http.NewRequest: %s", err.Error())
+	}
+	req = req.WithContext(ctx)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("client.Do: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	defer atomic.StoreInt32(&s.connected, 0)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, maxStreamLineSize)
+	for scanner.Scan() {
+		idle.Reset(streamIdleTimeout)
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var msg streamMessage
+		err = json.Unmarshal(line, &msg)
+		if err != nil {
+			return received, fmt.Errorf("json.Unmarshal: %s", err.Error())
+		}
+		if msg.Error != "" {
+			return received, fmt.Errorf("node error: %s", msg.Error)
+		}
+		received = true
+		atomic.StoreInt32(&s.connected, 1)
+		select {
+		case s.events <- msg.Result:
+		default:
+		}
+	}
+	if ctx.Err() != nil {
+		return received, fmt.Errorf("no events for %s", streamIdleTimeout)
+	}
+	if scanner.Err() != nil {
+		return received, fmt.Errorf("scanner.Err: %s", scanner.Err().Error())
+	}
+	return received, fmt.Errorf("connection closed")
+}
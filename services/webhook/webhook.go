@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/everstake/nebulas-tg-bot/log"
+	"github.com/everstake/nebulas-tg-bot/services/node"
+	"github.com/shopspring/decimal"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	EventTransfer        = "transfer"
+	EventVote            = "vote"
+	EventCancelVote      = "cancelVote"
+	EventStabilityChange = "stability_change"
+	EventNodeStatus      = "node_status"
+	EventReward          = "reward"
+	EventStaking         = "staking"
+
+	NodeStatusOffline     = "offline"
+	NodeStatusOnline      = "online"
+	NodeStatusApproved    = "approved"
+	NodeStatusUnapproved  = "unapproved"
+	NodeStatusTypeChanged = "type_changed"
+	NodeStatusRemoved     = "removed"
+
+	SignatureHeader = "X-Signature"
+	EventHeader     = "X-Event"
+
+	queueSize   = 1000
+	workers     = 4
+	maxAttempts = 5
+	retryDelay  = time.Second * 10
+)
+
+type (
+	Sender struct {
+		client *http.Client
+		queue  chan delivery
+	}
+	Event struct {
+		Event     string      `json:"event"`
+		Address   string      `json:"address"`
+		NodeID    string      `json:"node_id,omitempty"` // set for the callbacks of the node follows
+		Timestamp int64       `json:"timestamp"`
+		Data      interface{} `json:"data"`
+	}
+	TransferData struct {
+		Token       string           `json:"token"`
+		From        string           `json:"from"`
+		To          string           `json:"to"`
+		Value       decimal.Decimal  `json:"value"`
+		Transaction node.Transaction `json:"transaction"`
+	}
+	VoteData struct {
+		NodeID      string           `json:"node_id"`
+		Value       decimal.Decimal  `json:"value"`
+		Transaction node.Transaction `json:"transaction"`
+	}
+	StabilityData struct {
+		NodeID         string  `json:"node_id"`
+		StabilityIndex float64 `json:"stability_index"`
+		Previous       float64 `json:"previous"`
+	}
+	StakingData struct {
+		Function    string           `json:"function"`
+		Kind        string           `json:"kind"`
+		NodeID      string           `json:"node_id,omitempty"`
+		Account     string           `json:"account,omitempty"`
+		Role        string           `json:"role,omitempty"`
+		Value       decimal.Decimal  `json:"value"`
+		Args        []string         `json:"args"`
+		Transaction node.Transaction `json:"transaction"`
+	}
+	RewardData struct {
+		NodeID      string           `json:"node_id,omitempty"`
+		Value       decimal.Decimal  `json:"value"`
+		Transaction node.Transaction `json:"transaction"`
+	}
+	NodeStatusData struct {
+		NodeID string `json:"node_id"`
+		Status string `json:"status"`
+		Type   int    `json:"type"`
+	}
+	delivery struct {
+		url     string
+		secret  string
+		event   string
+		body    []byte
+		attempt int
+	}
+)
+
+// privateNetworks are not reachable from the internet, the callbacks to them are rejected.
+var privateNetworks = func() (networks []*net.IPNet) {
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+func NewSender() *Sender {
+	dialer := &net.Dialer{Timeout: time.Second * 10, Control: dialControl}
+	return &Sender{
+		client: &http.Client{
+			Timeout:   time.Second * 10,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
+		queue: make(chan delivery, queueSize),
+	}
+}
+
+// PublicIP reports whether the callbacks may be delivered to the ip.
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialControl checks the resolved address, so a public host name pointing to a private address is rejected too.
+func dialControl(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("address %s is not public", host)
+	}
+	return nil
+}
+
+func (s *Sender) Run() {
+	for i := 1; i < workers; i++ {
+		go s.work()
+	}
+	s.work()
+}
+
+// Send puts the event into the delivery queue, it never blocks the caller.
+func (s *Sender) Send(url string, secret string, event Event) {
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Webhook: json.Marshal: %s", err.Error())
+		return
+	}
+	s.enqueue(delivery{
+		url:    url,
+		secret: secret,
+		event:  event.Event,
+		body:   body,
+	})
+}
+
+func (s *Sender) enqueue(d delivery) {
+	select {
+	case s.queue <- d:
+	default:
+		log.Warn("Webhook: queue is full, event %s to %s dropped", d.event, d.url)
+	}
+}
+
+func (s *Sender) work() {
+	for d := range s.queue {
+		err := s.post(d)
+		if err == nil {
+			continue
+		}
+		d.attempt++
+		if d.attempt >= maxAttempts {
+			log.Warn("Webhook: delivery to %s failed after %d attempts: %s", d.url, d.attempt, err.Error())
+			continue
+		}
+		delay := retryDelay * time.Duration(1<<uint(d.attempt-1))
+		go func(d delivery) {
+			<-time.After(delay)
+			s.enqueue(d)
+		}(d)
+	}
+}
+
+func (s *Sender) post(d delivery) error {
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewBuffer(d.body))
+	if err != nil {
+		return fmt.Errorf("http.NewRequest: %s", err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, d.event)
+	req.Header.Set(SignatureHeader, Sign(d.secret, d.body))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("client.Do: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns hex encoded HMAC-SHA256 of the body, receivers use it to verify payloads.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
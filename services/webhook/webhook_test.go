@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "8.8.8.8", want: true},
+		{ip: "2001:4860:4860::8888", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "10.0.0.1", want: false},
+		{ip: "172.16.5.4", want: false},
+		{ip: "172.32.0.1", want: true},
+		{ip: "192.168.0.10", want: false},
+		{ip: "100.64.0.1", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "fd12:3456::1", want: false},
+		{ip: "224.0.0.1", want: false},
+	}
+	for _, test := range tests {
+		got := PublicIP(net.ParseIP(test.ip))
+		if got != test.want {
+			t.Errorf("PublicIP(%s) = %t, want %t", test.ip, got, test.want)
+		}
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	tests := []struct {
+		address string
+		ok      bool
+	}{
+		{address: "8.8.8.8:443", ok: true},
+		{address: "127.0.0.1:443", ok: false},
+		{address: "[::1]:443", ok: false},
+		{address: "10.0.0.1:443", ok: false},
+	}
+	for _, test := range tests {
+		err := dialControl("tcp", test.address, nil)
+		if (err == nil) != test.ok {
+			t.Errorf("dialControl(%s) error = %v", test.address, err)
+		}
+	}
+}